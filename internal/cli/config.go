@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+var configPath string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "путь к config.yaml (по умолчанию $"+specagent.ConfigEnvVar+" или поиск .spec_agent/config.yaml вверх от текущего каталога)")
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Работа с конфигурацией spec-agent",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Проверить config.yaml",
+	Long: `
+Команда config validate:
+- находит config.yaml (--config, $SPEC_AGENT_CONFIG или поиск вверх от текущего каталога)
+- строго разбирает YAML: неизвестные ключи считаются ошибкой
+- проверяет, что roots существуют, тема и шаблоны экспорта доступны,
+  названия секций и настройки lint корректны
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return fmt.Errorf("конфиг некорректен")
+		}
+
+		fmt.Printf("📄 Конфиг: %s\n", cfg.Path)
+		fmt.Printf("📁 Корень проекта: %s\n", cfg.Root)
+		fmt.Println()
+
+		problems := specagent.ValidateConfig(cfg)
+		for _, problem := range problems {
+			fmt.Printf("❌ %v\n", problem)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("конфиг некорректен: найдено проблем: %d", len(problems))
+		}
+
+		fmt.Println("✅ Конфиг корректен")
+		return nil
+	},
+}
+
+func loadConfig() (*specagent.Config, error) {
+	return specagent.LoadConfig(specagent.Options{ConfigPath: configPath})
+}
+
+func openProject(cache *specagent.Cache) (*specagent.Project, error) {
+	return specagent.Open(specagent.Options{ConfigPath: configPath, Cache: cache})
+}
+
+func projectLoader(cache *specagent.Cache) specagent.ProjectLoader {
+	return specagent.Loader(specagent.Options{ConfigPath: configPath, Cache: cache})
+}
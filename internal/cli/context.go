@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+const specRulesInline = `inline — see the "Specification Rules" section of this bundle`
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.Flags().StringP("format", "f", "markdown", "формат вывода: markdown или json")
+	contextCmd.Flags().Int("max-tokens", 0, "бюджет токенов: самые глубокие спеки отбрасываются первыми (0 — без ограничения)")
+	contextCmd.Flags().Bool("with-code", false, "добавить Go-файл рядом с каждой спекой (create_user.md → create_user.go)")
+	contextCmd.Flags().Bool("no-prompt", false, "не добавлять промт агента и правила спецификаций")
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context <entry>",
+	Short: "Собрать контекст для LLM по дереву спецификаций",
+	Long: `
+Команда context:
+- обходит дерево зависимостей от входной спецификации
+- собирает один документ: промт агента (с подставленными правилами),
+  spec_rules.md и все спеки дерева в порядке зависимостей
+  (сначала зависимости, входная спека последней)
+- промты берутся из .spec_agent/prompts/, если они там есть, иначе встроенные
+- с --max-tokens отбрасывает самые глубокие спеки, пока бюджет не сойдётся
+  (токены оцениваются приблизительно: 4 символа на токен)
+- с --with-code добавляет парные Go-файлы
+- печатает markdown или json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+		withCode, _ := cmd.Flags().GetBool("with-code")
+		noPrompt, _ := cmd.Flags().GetBool("no-prompt")
+
+		if format != "markdown" && format != "json" {
+			return fmt.Errorf("неизвестный формат: %s", format)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil && !errors.Is(err, specagent.ErrConfigNotFound) {
+			return err
+		}
+
+		names, err := specagent.SectionNamesFor(cfg, "")
+		if err != nil {
+			return err
+		}
+
+		opts := specagent.ContextOptions{MaxTokens: maxTokens, WithCode: withCode}
+		if !noPrompt {
+			prompts := specagent.Prompts(cfg)
+
+			vars, err := specagent.PromptVarsFor(cfg, prompts)
+			if err != nil {
+				return err
+			}
+			vars.SpecRulesSource = specRulesInline
+
+			prompt, err := specagent.RenderPrompt(prompts, "agent_prompt.md", vars)
+			if err != nil {
+				return err
+			}
+
+			opts.Prompt = prompt
+			opts.Rules = vars.SpecRules
+		}
+
+		bundle, err := specagent.BuildContext(args[0], names, opts)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(bundle, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		} else {
+			fmt.Print(bundle.Markdown())
+		}
+
+		fmt.Fprintf(os.Stderr, "📦 Спецификаций: %d, токенов: ~%d\n", len(bundle.Specs), bundle.Tokens)
+		if len(bundle.Omitted) > 0 {
+			fmt.Fprintf(os.Stderr, "✂️  Не вошли в бюджет: %d\n", len(bundle.Omitted))
+		}
+		if maxTokens > 0 && bundle.Tokens > maxTokens {
+			fmt.Fprintf(os.Stderr, "⚠️  Бюджет %d превышен даже без зависимостей\n", maxTokens)
+		}
+
+		return nil
+	},
+}
@@ -6,12 +6,17 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/SmirnovND/spec-agent/internal/config"
-	"github.com/SmirnovND/spec-agent/internal/spec"
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
 )
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("theme", "", "тема оформления: light или dark")
+	exportCmd.Flags().IntP("jobs", "j", 0, "количество параллельных воркеров (0 — по числу CPU)")
+	exportCmd.Flags().Bool("force", false, "перезаписать все файлы, игнорируя manifest.json")
+	exportCmd.Flags().Bool("rules", false, "перед экспортом запустить правила-плагины из config.yaml")
+	exportCmd.Flags().Bool("strict", false, "завершиться с кодом 1 или 2, если найдены проблемы графа")
+	addFormatFlag(exportCmd)
 }
 
 var exportCmd = &cobra.Command{
@@ -24,63 +29,93 @@ var exportCmd = &cobra.Command{
 - определяет root-спеки (на которые никто не ссылается)
 - строит граф зависимостей от этих корней
 - генерирует статичный HTML с навигацией и оглавлением
+- использует шаблоны из .spec_agent/templates/ (если они есть) или встроенные
 - сохраняет результат в .spec_agent/build/
+- перезаписывает только изменившиеся страницы (по хешам в manifest.json)
+  и удаляет устаревшие файлы
+- сообщает о проблемах: несуществующие roots, нечитаемые файлы, битые ссылки,
+  спеки, недостижимые ни из одного корня; экспорт при этом выполняется
+  и завершается с кодом 0, с --strict — с кодом 1 или 2
+- с --rules сначала запускает правила-плагины из config.yaml
+  и не экспортирует, если найдены нарушения уровня error
+- --format text|json|sarif|github задаёт формат диагностик; в форматах,
+  отличных от text, ход экспорта печатается в stderr
+- код выхода: 0 — HTML экспортирован, 1 — есть ошибки и экспорт не выполнен
+  (или --strict), 2 — только предупреждения и --strict, 3 — внутренний сбой
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		format, err := reportFormat(cmd)
 		if err != nil {
-			return fmt.Errorf("не удалось загрузить config.yaml: %w", err)
-		}
-
-		if len(cfg.Roots) == 0 {
-			return fmt.Errorf("в config.yaml не указаны roots")
+			return err
 		}
+		out := progressOutput(format)
 
-		specFiles, err := findSpecsNearRoots(cfg.Roots)
+		cfg, err := loadConfig()
 		if err != nil {
-			return err
+			return internalError(err)
 		}
 
-		if len(specFiles) == 0 {
-			return fmt.Errorf("не найдено ни одной спецификации рядом с roots")
+		project, diagnostics, err := specagent.OpenVerified(specagent.Options{Config: cfg})
+		if err != nil {
+			return internalError(err)
 		}
-
-		referenced := spec.CollectAllReferences(specFiles)
-
-		rootSpecs := spec.FindRootSpecs(specFiles, referenced)
-		if len(rootSpecs) == 0 {
-			return fmt.Errorf("не удалось определить корневые спецификации")
+		if project == nil {
+			return reportDiagnostics(format, cfg.Root, diagnostics)
 		}
 
-		fmt.Printf("🌳 Найдено %d корневых спецификаций:\n", len(rootSpecs))
+		rootSpecs := project.RootSpecs
+		fmt.Fprintf(out, "🌳 Найдено %d корневых спецификаций:\n", len(rootSpecs))
 		for _, root := range rootSpecs {
-			fmt.Printf("  - %s\n", root)
+			fmt.Fprintf(out, "  - %s\n", specagent.RelPath(root))
 		}
-		fmt.Println()
-
-		graph, err := spec.BuildGraphFromRoots(rootSpecs)
-		if err != nil {
-			return err
+		fmt.Fprintln(out)
+
+		graph := project.Graph
+		fmt.Fprintf(out, "📊 Граф содержит %d узлов и %d ребер\n", len(graph.Nodes), len(graph.Edges))
+		fmt.Fprintln(out)
+
+		if rules, _ := cmd.Flags().GetBool("rules"); rules {
+			found, err := ruleDiagnostics(project)
+			if err != nil {
+				return internalError(err)
+			}
+			diagnostics = append(diagnostics, found...)
+
+			if specagent.HasErrors(found) {
+				fmt.Fprintln(out, "⛔ Правила нашли ошибки, экспорт пропущен")
+				fmt.Fprintln(out)
+				return reportDiagnostics(format, cfg.Root, diagnostics)
+			}
 		}
 
-		fmt.Printf("📊 Граф содержит %d узлов и %d ребер\n", len(graph.Nodes), len(graph.Edges))
-		fmt.Println()
+		outputDir := project.Config.BuildDir()
+		fmt.Fprintf(out, "📝 Генерирую HTML в %s...\n", specagent.RelPath(outputDir))
 
-		outputDir := filepath.Join(".spec_agent", "build")
-		fmt.Printf("📝 Генерирую HTML в %s...\n", outputDir)
+		opts := specagent.ExportOptions{OutputDir: outputDir}
+		opts.Theme, _ = cmd.Flags().GetString("theme")
+		opts.Workers, _ = cmd.Flags().GetInt("jobs")
+		opts.Force, _ = cmd.Flags().GetBool("force")
 
-		if err := spec.ExportToHTML(graph, outputDir); err != nil {
-			return fmt.Errorf("ошибка при экспорте: %w", err)
+		result, err := project.Export(opts)
+		if err != nil {
+			return internalError(err)
 		}
 
+		fmt.Fprintf(out, "📄 Записано файлов: %d, без изменений: %d, удалено: %d\n",
+			len(result.Written), len(result.Unchanged), len(result.Removed))
+
 		indexPath := filepath.Join(outputDir, "index.html")
 		absPath, _ := filepath.Abs(indexPath)
 
-		fmt.Println()
-		fmt.Printf("✅ HTML экспортирован успешно!\n")
-		fmt.Printf("📂 Файлы находятся в: %s\n", outputDir)
-		fmt.Printf("🌐 Откройте в браузере: file://%s\n", absPath)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "✅ HTML экспортирован успешно!\n")
+		fmt.Fprintf(out, "📂 Файлы находятся в: %s\n", specagent.RelPath(outputDir))
+		fmt.Fprintf(out, "🌐 Откройте в браузере: file://%s\n", absPath)
+		fmt.Fprintln(out)
 
-		return nil
+		if strict, _ := cmd.Flags().GetBool("strict"); strict {
+			return reportDiagnostics(format, cfg.Root, diagnostics)
+		}
+		return printDiagnostics(format, cfg.Root, diagnostics)
 	},
 }
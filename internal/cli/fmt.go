@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+	fmtCmd.Flags().Bool("check", false, "не изменять файлы, завершиться с ошибкой, если форматирование требуется")
+	fmtCmd.Flags().Bool("diff", false, "показать изменения в формате unified diff, не изменяя файлы")
+}
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [spec...]",
+	Short: "Привести спецификации к каноническому виду",
+	Long: `
+Команда fmt:
+- без аргументов форматирует все спецификации графа из config.yaml
+- упорядочивает секции как в spec_rules.md и добавляет пустые обязательные секции на языке существующих заголовков
+- перенумеровывает Business Rules и шаги Flow
+- приводит ссылки Flow к виду → kind: path#anchor
+- сортирует Dependencies, убирает дубли и добавляет спеки, упомянутые во Flow
+- с --check только проверяет (для CI), с --diff показывает изменения
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		diff, _ := cmd.Flags().GetBool("diff")
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		names, err := specagent.SectionNamesFor(cfg, "")
+		if err != nil {
+			return err
+		}
+
+		files := args
+		if len(files) == 0 {
+			project, err := openProject(nil)
+			if err != nil {
+				return err
+			}
+			for _, path := range project.Paths() {
+				if _, err := os.Stat(path); err == nil {
+					files = append(files, path)
+				}
+			}
+		}
+
+		changed := 0
+
+		for _, path := range files {
+			s, err := specagent.ParseFile(path, names)
+			if err != nil {
+				return fmt.Errorf("не удалось прочитать спецификацию: %w", err)
+			}
+
+			formatted := specagent.Format(s, names)
+			if formatted == s.Content {
+				continue
+			}
+			changed++
+
+			rel := specagent.RelPath(path)
+			switch {
+			case diff:
+				fmt.Print(specagent.UnifiedDiff("a/"+rel, "b/"+rel, s.Content, formatted))
+			case check:
+				fmt.Printf("❌ %s\n", rel)
+			default:
+				info, err := os.Stat(path)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+					return fmt.Errorf("не удалось записать %s: %w", rel, err)
+				}
+				fmt.Printf("✏️  %s\n", rel)
+			}
+		}
+
+		if changed == 0 {
+			fmt.Println("✅ Все спецификации отформатированы")
+			return nil
+		}
+
+		if check {
+			return fmt.Errorf("требуется форматирование файлов: %d (запустите spec-agent fmt)", changed)
+		}
+		if !diff {
+			fmt.Printf("📄 Отформатировано файлов: %d\n", changed)
+		}
+
+		return nil
+	},
+}
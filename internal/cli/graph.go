@@ -2,17 +2,16 @@ package cli
 
 import (
 	"fmt"
-	"io/fs"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
-	"github.com/SmirnovND/spec-agent/internal/config"
-	"github.com/SmirnovND/spec-agent/internal/spec"
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
 )
 
 func init() {
 	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().Bool("rules", false, "запустить правила-плагины из config.yaml")
+	addFormatFlag(graphCmd)
 }
 
 var graphCmd = &cobra.Command{
@@ -24,67 +23,52 @@ var graphCmd = &cobra.Command{
 - находит спеки рядом с указанными roots
 - определяет root-спеки (на которые никто не ссылается)
 - строит граф зависимостей от этих корней
+- сообщает о проблемах: несуществующие roots, нечитаемые файлы, битые ссылки,
+  спеки, недостижимые ни из одного корня
+- с --rules запускает правила-плагины из config.yaml (см. spec-agent rules run)
+- --format text|json|sarif|github задаёт формат диагностик; в форматах,
+  отличных от text, сводка графа печатается в stderr
+- код выхода: 1 — есть ошибки, 2 — только предупреждения, 3 — внутренний сбой
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		format, err := reportFormat(cmd)
 		if err != nil {
-			return fmt.Errorf("не удалось загрузить config.yaml: %w", err)
+			return err
 		}
+		out := progressOutput(format)
 
-		if len(cfg.Roots) == 0 {
-			return fmt.Errorf("в config.yaml не указаны roots")
+		cfg, err := loadConfig()
+		if err != nil {
+			return internalError(err)
 		}
 
-		specFiles, err := findSpecsNearRoots(cfg.Roots)
+		project, diagnostics, err := specagent.OpenVerified(specagent.Options{Config: cfg})
 		if err != nil {
-			return err
+			return internalError(err)
 		}
-
-		if len(specFiles) == 0 {
-			return fmt.Errorf("не найдено ни одной спецификации рядом с roots")
+		if project == nil {
+			return reportDiagnostics(format, cfg.Root, diagnostics)
 		}
 
-		referenced := spec.CollectAllReferences(specFiles)
-
-		rootSpecs := spec.FindRootSpecs(specFiles, referenced)
-		if len(rootSpecs) == 0 {
-			return fmt.Errorf("не удалось определить корневые спецификации")
-		}
-
-		fmt.Printf("🌳 Найдено %d корневых спецификаций:\n", len(rootSpecs))
+		rootSpecs := project.RootSpecs
+		fmt.Fprintf(out, "🌳 Найдено %d корневых спецификаций:\n", len(rootSpecs))
 		for _, root := range rootSpecs {
-			fmt.Printf("  - %s\n", root)
-		}
-		fmt.Println()
-
-		graph, err := spec.BuildGraphFromRoots(rootSpecs)
-		if err != nil {
-			return err
+			fmt.Fprintf(out, "  - %s\n", specagent.RelPath(root))
 		}
+		fmt.Fprintln(out)
 
-		fmt.Printf("📊 Граф содержит %d узлов и %d ребер\n", len(graph.Nodes), len(graph.Edges))
-
-		return nil
-	},
-}
+		graph := project.Graph
+		fmt.Fprintf(out, "📊 Граф содержит %d узлов и %d ребер\n", len(graph.Nodes), len(graph.Edges))
+		fmt.Fprintln(out)
 
-func findSpecsNearRoots(roots []string) ([]string, error) {
-	var specs []string
-
-	for _, root := range roots {
-		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if rules, _ := cmd.Flags().GetBool("rules"); rules {
+			found, err := ruleDiagnostics(project)
 			if err != nil {
-				return err
-			}
-			if filepath.Ext(path) == ".md" {
-				specs = append(specs, path)
+				return internalError(err)
 			}
-			return nil
-		})
-		if err != nil {
-			return nil, err
+			diagnostics = append(diagnostics, found...)
 		}
-	}
 
-	return specs, nil
+		return reportDiagnostics(format, cfg.Root, diagnostics)
+	},
 }
@@ -1,12 +1,20 @@
 package cli
 
 import (
-	"github.com/SmirnovND/spec-agent/internal/fs"
+	"fmt"
+	"os"
+	"slices"
+
 	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
 )
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().Bool("force", false, "перезаписать существующие файлы, включая config.yaml")
+	initCmd.Flags().Bool("upgrade", false, "обновить только не изменённые локально встроенные ресурсы")
+	initCmd.MarkFlagsMutuallyExclusive("force", "upgrade")
 }
 
 var initCmd = &cobra.Command{
@@ -19,14 +27,73 @@ var initCmd = &cobra.Command{
   - config.yaml — файл с корневыми путями (roots)
   - examples/ — примеры спецификаций
   - prompts/ — промты для LLM
+  - templates.example/ — встроенные шаблоны и темы HTML-экспорта для образца;
+    чтобы переопределить файл, скопируйте его в templates/
   - README.md — документация по ресурсам
+  - assets.json — хеши установленных ресурсов для --upgrade
 - spec_changes/ — директория для планов изменений
 
+Проект инициализируется в корне Go-модуля (каталог с go.mod), roots
+определяются по каталогам слоёв: controllers, handlers, usecases, services,
+repositories и т.п.
+
+Существующие файлы не перезаписываются:
+- --upgrade обновляет встроенные ресурсы, которые не менялись локально
+- нетронутые копии шаблонов, установленные прежними версиями в templates/,
+  удаляются, чтобы не заслонять встроенные
+- --force перезаписывает всё, включая config.yaml
+
 Все ресурсы встраиваются в бинарь и автоматически распаковываются.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := fs.InitSpecAgent(); err != nil {
+		force, _ := cmd.Flags().GetBool("force")
+		upgrade, _ := cmd.Flags().GetBool("upgrade")
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		dir, isModule := specagent.FindModuleRoot(wd)
+		roots := specagent.DetectRoots(dir)
+
+		fmt.Printf("📁 Корень проекта: %s\n", dir)
+		if !isModule {
+			fmt.Println("⚠️  go.mod не найден, используется текущий каталог")
+		}
+
+		result, err := specagent.Init(specagent.InitOptions{
+			Dir:     dir,
+			Roots:   roots,
+			Force:   force,
+			Upgrade: upgrade,
+		})
+		if err != nil {
 			return err
 		}
+
+		for _, path := range result.Created {
+			fmt.Printf("✨ %s\n", path)
+		}
+		for _, path := range result.Updated {
+			fmt.Printf("🔄 %s\n", path)
+		}
+		for _, path := range result.Removed {
+			fmt.Printf("🗑️  %s — устаревшая копия ресурса удалена\n", path)
+		}
+		for _, path := range result.Modified {
+			fmt.Printf("⏭️  %s — изменён локально, не перезаписан\n", path)
+		}
+
+		if slices.Contains(result.Created, ".spec_agent/config.yaml") || slices.Contains(result.Updated, ".spec_agent/config.yaml") {
+			fmt.Println("\n🌳 Roots:")
+			for _, root := range roots {
+				fmt.Printf("  - %s\n", root)
+			}
+		}
+
+		fmt.Printf("\n✅ Создано: %d, обновлено: %d, удалено: %d, без изменений: %d, пропущено: %d\n",
+			len(result.Created), len(result.Updated), len(result.Removed), len(result.Unchanged), len(result.Modified))
+
 		return nil
 	},
 }
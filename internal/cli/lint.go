@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().Bool("fix", false, "привести заголовки секций к каноническим названиям")
+	lintCmd.Flags().String("lang", "", "язык заголовков для --fix: en или ru (по умолчанию sections.language из config.yaml)")
+	addFormatFlag(lintCmd)
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Проверить спецификации на соответствие правилам",
+	Long: `
+Команда lint:
+- читает .spec_agent/config.yaml
+- строит граф спецификаций от roots
+- проверяет наличие обязательных секций и битые ссылки
+- проверяет язык текста секций (lint.language в config.yaml)
+- ищет запрещённые практики: SQL, HTTP вне контроллеров, ссылки на строки кода
+- сверяет Dependencies со ссылками из Flow
+- правила отключаются через lint.disable или <!-- spec-agent-disable правило -->
+- заголовки секций распознаются на русском и английском (sections.aliases в config.yaml)
+- с --fix переименовывает заголовки в канонические для выбранного языка
+- --format text|json|sarif|github задаёт формат вывода нарушений
+- код выхода: 1 — есть ошибки, 2 — только предупреждения, 3 — внутренний сбой
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fix, _ := cmd.Flags().GetBool("fix")
+		lang, _ := cmd.Flags().GetString("lang")
+
+		format, err := reportFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		project, err := specagent.Open(specagent.Options{ConfigPath: configPath, SectionLanguage: lang})
+		if err != nil {
+			return internalError(err)
+		}
+
+		if fix {
+			if err := fixSectionHeadings(project, progressOutput(format)); err != nil {
+				return internalError(err)
+			}
+		}
+
+		return reportDiagnostics(format, project.Config.Root, project.Lint())
+	},
+}
+
+func fixSectionHeadings(project *specagent.Project, out io.Writer) error {
+	headings, files := 0, 0
+
+	for _, path := range project.Paths() {
+		s, err := project.Spec(path)
+		if err != nil {
+			continue
+		}
+
+		content, n := specagent.NormalizeSectionHeadings(s, project.SectionNames())
+		if n == 0 {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("не удалось записать %s: %w", specagent.RelPath(path), err)
+		}
+
+		project.Cache.Invalidate(path)
+		fmt.Fprintf(out, "🔧 %s: переименовано заголовков: %d\n", specagent.RelPath(path), n)
+		headings += n
+		files++
+	}
+
+	if files > 0 {
+		fmt.Fprintf(out, "🔧 Исправлено заголовков: %d в %d файлах\n\n", headings, files)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+	lsCmd.Flags().BoolP("all", "a", false, "показать также пропущенные файлы и каталоги с причиной")
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Показать, какие файлы считаются спецификациями",
+	Long: `
+Команда ls:
+- обходит roots из config.yaml так же, как graph, export и serve
+- применяет specs.include / specs.exclude, исключения по умолчанию и .gitignore
+- выводит найденные спецификации
+- с --all показывает пропущенные файлы и каталоги и причину пропуска
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		files, err := specagent.Discover(cfg)
+		if err != nil {
+			return err
+		}
+
+		found, skipped := 0, 0
+		for _, f := range files {
+			rel := specagent.RelPath(f.Path)
+			if f.Dir {
+				rel += "/"
+			}
+
+			if f.Included {
+				found++
+				fmt.Printf("✅ %s\n", rel)
+				continue
+			}
+
+			skipped++
+			if all {
+				fmt.Printf("⏭️  %s — %s\n", rel, f.Reason)
+			}
+		}
+
+		fmt.Println()
+		fmt.Printf("📄 Спецификаций: %d, пропущено: %d\n", found, skipped)
+
+		return nil
+	},
+}
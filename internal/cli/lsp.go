@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Запустить Language Server (stdio) для спецификаций",
+	Long: `
+Команда lsp:
+- запускает Language Server Protocol поверх stdin/stdout для .md спецификаций
+- переход к определению по ссылкам ../x.md и ../x.md#Method
+- поиск ссылок на спецификацию (обратные ссылки из других спек)
+- автодополнение путей спек и якорей после → calls:/reads:/writes:/uses:/validates:
+- подсказка при наведении: секция Responsibility целевой спеки
+- диагностика spec-agent lint при открытии и сохранении файла
+- без config.yaml работают переход, подсказки и диагностика текущего файла
+- журнал пишется в stderr
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := specagent.NewLSPServer(specagent.LSPOptions{
+			Load:   projectLoader(nil),
+			Logger: log.New(os.Stderr, "spec-agent lsp: ", log.LstdFlags),
+		})
+
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Запустить MCP-сервер (stdio) для AI-агентов",
+	Long: `
+Команда mcp:
+- запускает сервер Model Context Protocol поверх stdin/stdout
+- даёт агенту инструменты get_spec, spec_tree, impact, lint,
+  create_change_plan и search_specs вместо чтения спек grep'ом
+- пути в аргументах и ответах — относительно корня проекта,
+  принимаются только спеки из графа
+- спеки перечитываются при каждом вызове, правки агента видны сразу
+- stdout занят протоколом, поэтому команда ничего в него не печатает
+
+Пример для клиента MCP:
+  {"command": "spec-agent", "args": ["mcp"]}
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := specagent.NewMCPServer(specagent.MCPOptions{
+			Load: projectLoader(nil),
+			Root: cfg.Root,
+		})
+
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.Flags().String("rules", "inline", "источник правил: inline, path или свой путь/URL")
+	promptCmd.Flags().Bool("list", false, "показать доступные промты")
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt [name]",
+	Short: "Отрисовать промт агента с подставленными переменными",
+	Long: `
+Команда prompt:
+- берёт промт из .spec_agent/prompts/ (если переопределён) или встроенный
+- отрисовывает его как Go text/template (по умолчанию agent_prompt.md)
+- с --rules inline дописывает spec_rules.md в конец,
+  с --rules path ссылается на .spec_agent/prompts/spec_rules.md,
+  любое другое значение подставляется как путь или URL
+
+Переменные шаблона:
+  {{.SpecRulesSource}}  откуда брать правила
+  {{.SpecRules}}        текст spec_rules.md
+  {{.Layers}}           слои проекта из roots в порядке вызовов (join .Layers " → ")
+  {{.Roots}}            roots из config.yaml
+  {{.Language}}         язык спецификаций из lint.language.require (пусто при off)
+  {{.LanguageCode}}     код языка: ru, en или off
+  {{.Date}}             текущая дата, 2006-01-02
+  {{.PlanPrefix}}       префикс имени плана изменений, 20060102_1504
+
+Старый плейсхолдер {{SPEC_RULES_SOURCE}} по-прежнему поддерживается.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rules, _ := cmd.Flags().GetString("rules")
+		list, _ := cmd.Flags().GetBool("list")
+
+		cfg, err := loadConfig()
+		if err != nil && !errors.Is(err, specagent.ErrConfigNotFound) {
+			return err
+		}
+
+		prompts := specagent.Prompts(cfg)
+
+		if list {
+			names, err := fs.Glob(prompts, "*.md")
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(strings.TrimSuffix(name, ".md"))
+			}
+			return nil
+		}
+
+		name := "agent_prompt.md"
+		if len(args) == 1 {
+			name = args[0]
+			if !strings.HasSuffix(name, ".md") {
+				name += ".md"
+			}
+		}
+
+		vars, err := specagent.PromptVarsFor(cfg, prompts)
+		if err != nil {
+			return err
+		}
+
+		switch rules {
+		case "inline":
+			vars.SpecRulesSource = `inline — see the SPECIFICATION RULES DOCUMENT at the end of this prompt`
+		case "path":
+			vars.SpecRulesSource = filepath.ToSlash(filepath.Join(specagent.PromptsDir, specagent.SpecRulesPrompt))
+		default:
+			vars.SpecRulesSource = rules
+		}
+
+		text, err := specagent.RenderPrompt(prompts, name, vars)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("промт %s не найден (список: spec-agent prompt --list)", strings.TrimSuffix(name, ".md"))
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(strings.TrimRight(text, "\n"), "\n")
+		if rules == "inline" && name != specagent.SpecRulesPrompt {
+			fmt.Print("\n--------------------------------\nSPECIFICATION RULES DOCUMENT\n--------------------------------\n\n")
+			fmt.Print(strings.TrimRight(vars.SpecRules, "\n"), "\n")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+const (
+	ExitErrors   = 1
+	ExitWarnings = 2
+	ExitInternal = 3
+)
+
+type ExitError struct {
+	Code   int
+	Err    error
+	Silent bool
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().String("format", specagent.FormatText, "формат диагностик: "+strings.Join(specagent.ReportFormats, ", "))
+}
+
+func reportFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if !slices.Contains(specagent.ReportFormats, format) {
+		return "", fmt.Errorf("неизвестный формат: %s (допустимо: %s)", format, strings.Join(specagent.ReportFormats, ", "))
+	}
+
+	return format, nil
+}
+
+func progressOutput(format string) io.Writer {
+	if format == specagent.FormatText {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+func internalError(err error) error {
+	var exitErr *ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitError{Code: ExitInternal, Err: err}
+}
+
+func reportDiagnostics(format, root string, diagnostics []specagent.Diagnostic) error {
+	if err := printDiagnostics(format, root, diagnostics); err != nil {
+		return err
+	}
+
+	errorCount, warningCount := specagent.CountSeverities(diagnostics)
+	switch {
+	case errorCount > 0:
+		return &ExitError{Code: ExitErrors, Err: fmt.Errorf("спецификации содержат ошибки"), Silent: true}
+	case warningCount > 0:
+		return &ExitError{Code: ExitWarnings, Err: fmt.Errorf("спецификации содержат предупреждения"), Silent: true}
+	}
+	return nil
+}
+
+func printDiagnostics(format, root string, diagnostics []specagent.Diagnostic) error {
+	err := specagent.WriteDiagnostics(os.Stdout, diagnostics, specagent.ReportOptions{Format: format, Root: root})
+	if err != nil {
+		return internalError(err)
+	}
+
+	errorCount, warningCount := specagent.CountSeverities(diagnostics)
+
+	if format == specagent.FormatText {
+		if len(diagnostics) == 0 {
+			fmt.Println("✅ Нарушений не найдено")
+		} else {
+			fmt.Println()
+			fmt.Printf("Найдено нарушений: %d (ошибок: %d, предупреждений: %d)\n", len(diagnostics), errorCount, warningCount)
+		}
+	}
+	return nil
+}
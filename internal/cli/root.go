@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
@@ -9,8 +13,16 @@ var rootCmd = &cobra.Command{
 	Short: "CLI для работы со spec-driven архитектурой",
 	Long: `spec-agent — инструмент для работы со спецификациями,
 которые управляют архитектурой и изменениями в коде.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+
+	var exitErr *ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.Silent) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return err
 }
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesRunCmd)
+	addFormatFlag(rulesRunCmd)
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Пользовательские правила проверки спецификаций",
+}
+
+var rulesRunCmd = &cobra.Command{
+	Use:   "run [name...]",
+	Short: "Запустить правила-плагины из config.yaml",
+	Long: `
+Команда rules run:
+- строит граф спецификаций от roots
+- запускает исполняемые файлы из секции rules в config.yaml (или только указанные по имени)
+- передаёт каждому на stdin JSON с разобранными спеками и графом
+- читает со stdout JSON с находками: file, line, severity, message (без нарушений — [])
+- код выхода правила 1 без находок или любой другой ненулевой — сбой правила
+- находки отключаются так же, как правила lint: <!-- spec-agent-disable имя/правило -->
+- --format text|json|sarif|github задаёт формат вывода находок
+- код выхода: 1 — есть ошибки, 2 — только предупреждения, 3 — сбой правила или конфига
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := reportFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		project, err := openProject(nil)
+		if err != nil {
+			return internalError(err)
+		}
+
+		diagnostics, err := ruleDiagnostics(project, args...)
+		if err != nil {
+			return internalError(err)
+		}
+
+		return reportDiagnostics(format, project.Config.Root, diagnostics)
+	},
+}
+
+func ruleDiagnostics(project *specagent.Project, names ...string) ([]specagent.Diagnostic, error) {
+	if len(project.Config.Rules) == 0 {
+		return nil, fmt.Errorf("в config.yaml не настроены правила (rules)")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return project.RunRules(ctx, names...)
+}
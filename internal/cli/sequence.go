@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
+)
+
+func init() {
+	rootCmd.AddCommand(sequenceCmd)
+	sequenceCmd.Flags().IntP("depth", "d", 0, "глубина рекурсии во Flow вызываемых спек")
+	sequenceCmd.Flags().StringP("format", "f", "mermaid", "формат вывода: mermaid или svg")
+}
+
+var sequenceCmd = &cobra.Command{
+	Use:   "sequence <spec>",
+	Short: "Построить диаграмму последовательности по секции Flow",
+	Long: `
+Команда sequence:
+- читает секцию ## Flow указанной спецификации
+- превращает шаги и ссылки → calls/reads/writes: в сообщения
+- при --depth > 0 раскрывает Flow вызываемых спецификаций
+- печатает Mermaid sequenceDiagram или SVG
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		depth, _ := cmd.Flags().GetInt("depth")
+		format, _ := cmd.Flags().GetString("format")
+
+		cfg, err := loadConfig()
+		if err != nil && !errors.Is(err, specagent.ErrConfigNotFound) {
+			return err
+		}
+
+		names, err := specagent.SectionNamesFor(cfg, "")
+		if err != nil {
+			return err
+		}
+
+		seq, err := specagent.BuildSequence(args[0], depth, names)
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать спецификацию: %w", err)
+		}
+
+		switch format {
+		case "mermaid":
+			fmt.Print(seq.Mermaid())
+		case "svg":
+			fmt.Print(seq.SVG())
+		default:
+			return fmt.Errorf("неизвестный формат: %s", format)
+		}
+
+		return nil
+	},
+}
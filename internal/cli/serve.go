@@ -1,24 +1,39 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net"
-	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/SmirnovND/spec-agent/internal/config"
-	"github.com/SmirnovND/spec-agent/internal/spec"
+	"github.com/SmirnovND/spec-agent/pkg/specagent"
 )
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
 	serveCmd.Flags().StringP("port", "p", "8080", "порт для сервера")
 	serveCmd.Flags().String("host", "localhost", "хост для привязки")
+	serveCmd.Flags().BoolP("watch", "w", false, "следить за спеками, пересобирать страницы и перезагружать вкладки браузера")
+	serveCmd.Flags().Duration("interval", time.Second, "интервал опроса файлов в режиме --watch")
+	serveCmd.Flags().Bool("edit", false, "включить редактор спецификаций в браузере (/edit/{path})")
+	serveCmd.Flags().String("auth", os.Getenv("SPEC_AGENT_EDIT_AUTH"), "логин:пароль для basic-auth редактора (по умолчанию из SPEC_AGENT_EDIT_AUTH)")
+	serveCmd.Flags().Bool("open", false, "открыть сервер в браузере после запуска")
+	serveCmd.Flags().BoolP("quiet", "q", false, "не логировать HTTP-запросы")
+	serveCmd.Flags().Duration("read-timeout", 15*time.Second, "таймаут чтения запроса")
+	serveCmd.Flags().Duration("write-timeout", 30*time.Second, "таймаут записи ответа")
+	serveCmd.Flags().Duration("idle-timeout", 60*time.Second, "таймаут простаивающего keep-alive соединения")
+	serveCmd.Flags().Duration("shutdown-timeout", 5*time.Second, "время на завершение активных запросов при остановке")
 }
 
 var serveCmd = &cobra.Command{
@@ -30,111 +45,204 @@ var serveCmd = &cobra.Command{
 - запускает встроенный HTTP сервер
 - обслуживает файлы из .spec_agent/build/
 - доступна по http://localhost:8080
-- прекращает работу по Ctrl+C
+- отдаёт read-only JSON API: /api/specs, /api/specs/{path}, /api/graph,
+  /api/impact/{path}, /api/lint
+- с --watch следит за roots, шаблонами и config.yaml, инкрементально
+  пересобирает изменившиеся страницы и перезагружает открытые вкладки (SSE)
+- с --edit открывает редактор спецификаций с проверкой и предпросмотром;
+  сохранение, добавляющее битые ссылки или удаляющее обязательные секции,
+  отклоняется
+- по Ctrl+C дожидается завершения активных запросов и останавливается
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port, _ := cmd.Flags().GetString("port")
 		host, _ := cmd.Flags().GetString("host")
+		watchMode, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		open, _ := cmd.Flags().GetBool("open")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		edit, _ := cmd.Flags().GetBool("edit")
+		auth, _ := cmd.Flags().GetString("auth")
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
 
-		buildDir := filepath.Join(".spec_agent", "build")
+		buildDir := cfg.BuildDir()
 		indexPath := filepath.Join(buildDir, "index.html")
+		cache := specagent.NewCache()
 
-		if _, err := os.Stat(indexPath); os.IsNotExist(err) {
-			fmt.Println("📝 Спецификации не сгенерированы. Генерирую...")
+		if _, err := os.Stat(indexPath); os.IsNotExist(err) || watchMode {
+			fmt.Println("📝 Генерирую спецификации...")
 			fmt.Println()
 
-			if err := generateSpecs(); err != nil {
+			if _, err := generateSpecs(cache); err != nil {
 				return fmt.Errorf("ошибка при генерации: %w", err)
 			}
 
 			fmt.Println()
 		}
 
-		return serveFiles(host, port, buildDir)
-	},
-}
+		var apiCache *specagent.Cache
+		if watchMode {
+			apiCache = cache
+		}
 
-func generateSpecs() error {
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("не удалось загрузить config.yaml: %w", err)
-	}
+		var poller *specagent.Poller
+		if watchMode {
+			poller = specagent.NewPoller(specagent.WatchPaths(cfg), interval)
+		}
 
-	if len(cfg.Roots) == 0 {
-		return fmt.Errorf("в config.yaml не указаны roots")
-	}
+		var rebuildMu sync.Mutex
+		rebuild := func(changed []string) bool {
+			rebuildMu.Lock()
+			defer rebuildMu.Unlock()
+			return rebuildOnChange(cache, cfg, changed)
+		}
 
-	specFiles, err := findSpecsNearRoots(cfg.Roots)
-	if err != nil {
-		return err
-	}
+		var srv *specagent.Server
+		opts := specagent.ServerOptions{
+			Addr:         net.JoinHostPort(host, port),
+			BuildDir:     buildDir,
+			Root:         cfg.Root,
+			Loader:       projectLoader(apiCache),
+			LiveReload:   watchMode,
+			Edit:         edit,
+			BasicAuth:    auth,
+			TemplatesDir: cfg.TemplatesDir(),
+			OnSave: func(path string) {
+				if poller != nil {
+					poller.Mark(path)
+				}
+				if rebuild([]string{path}) {
+					srv.Reload()
+				}
+			},
+		}
+		opts.ReadTimeout, _ = cmd.Flags().GetDuration("read-timeout")
+		opts.WriteTimeout, _ = cmd.Flags().GetDuration("write-timeout")
+		opts.IdleTimeout, _ = cmd.Flags().GetDuration("idle-timeout")
+		opts.ShutdownTimeout, _ = cmd.Flags().GetDuration("shutdown-timeout")
+		if !quiet {
+			opts.Logger = log.New(os.Stdout, "🌐 ", log.Ltime)
+		}
 
-	if len(specFiles) == 0 {
-		return fmt.Errorf("не найдено ни одной спецификации рядом с roots")
-	}
+		srv = specagent.NewServer(opts)
 
-	referenced := spec.CollectAllReferences(specFiles)
-	rootSpecs := spec.FindRootSpecs(specFiles, referenced)
-	if len(rootSpecs) == 0 {
-		return fmt.Errorf("не удалось определить корневые спецификации")
-	}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if poller != nil {
+			go poller.Run(ctx, func(changed []string) {
+				if rebuild(changed) {
+					srv.Reload()
+				}
+			})
+		}
+
+		addr, err := srv.Listen()
+		if err != nil {
+			return fmt.Errorf("ошибка сервера: %w", err)
+		}
+
+		url := fmt.Sprintf("http://%s", addr)
+		absPath, _ := filepath.Abs(buildDir)
 
-	fmt.Printf("🌳 Найдено %d корневых спецификаций\n", len(rootSpecs))
+		fmt.Println()
+		fmt.Printf("🚀 Веб-сервер запущен!\n")
+		fmt.Printf("🌐 Откройте в браузере: %s\n", url)
+		fmt.Printf("📂 Обслуживаются файлы из: %s\n", absPath)
+		if watchMode {
+			fmt.Printf("👀 Режим наблюдения: страницы обновляются автоматически\n")
+		}
+		if edit {
+			fmt.Printf("✏️  Редактор включён: %s/edit/{путь к спеке}\n", url)
+			if auth == "" && host != "localhost" && host != "127.0.0.1" {
+				fmt.Printf("⚠️  Редактор доступен без авторизации, задайте --auth логин:пароль\n")
+			}
+		}
+		fmt.Println()
+		fmt.Println("Нажмите Ctrl+C для выключения сервера")
+		fmt.Println()
 
-	graph, err := spec.BuildGraphFromRoots(rootSpecs)
+		if open {
+			if err := openBrowser(url); err != nil {
+				fmt.Printf("⚠️  Не удалось открыть браузер: %v\n", err)
+			}
+		}
+
+		if err := srv.Serve(ctx); err != nil {
+			return fmt.Errorf("ошибка сервера: %w", err)
+		}
+
+		fmt.Println()
+		fmt.Println("⏹️  Сервер выключен")
+		return nil
+	},
+}
+
+func generateSpecs(cache *specagent.Cache) (*specagent.ExportResult, error) {
+	project, err := openProject(cache)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Printf("📊 Граф содержит %d узлов и %d ребер\n", len(graph.Nodes), len(graph.Edges))
+	fmt.Printf("🌳 Найдено %d корневых спецификаций\n", len(project.RootSpecs))
+	fmt.Printf("📊 Граф содержит %d узлов и %d ребер\n", len(project.Graph.Nodes), len(project.Graph.Edges))
 
-	buildDir := filepath.Join(".spec_agent", "build")
-	if err := spec.ExportToHTML(graph, buildDir); err != nil {
-		return fmt.Errorf("ошибка при экспорте: %w", err)
+	result, err := project.Export(specagent.ExportOptions{})
+	if err != nil {
+		return nil, err
 	}
 
 	fmt.Println("✅ HTML сгенерирован успешно!")
-	return nil
+	return result, nil
 }
 
-func serveFiles(host, port, buildDir string) error {
-	absPath, _ := filepath.Abs(buildDir)
-
-	fs := http.FileServer(http.Dir(buildDir))
+func rebuildOnChange(cache *specagent.Cache, cfg *specagent.Config, changed []string) bool {
+	absBuild := cfg.BuildDir()
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/" {
-			r.URL.Path = "/index.html"
+	var relevant []string
+	for _, path := range changed {
+		if !strings.HasPrefix(path, absBuild+string(filepath.Separator)) {
+			relevant = append(relevant, path)
 		}
-		fs.ServeHTTP(w, r)
-	})
-
-	addr := net.JoinHostPort(host, port)
-	server := &http.Server{
-		Addr: addr,
+	}
+	if len(relevant) == 0 {
+		return false
 	}
 
 	fmt.Println()
-	fmt.Printf("🚀 Веб-сервер запущен!\n")
-	fmt.Printf("🌐 Откройте в браузере: http://%s:%s\n", host, port)
-	fmt.Printf("📂 Обслуживаются файлы из: %s\n", absPath)
-	fmt.Println()
-	fmt.Println("Нажмите Ctrl+C для выключения сервера")
-	fmt.Println()
+	fmt.Printf("🔄 Изменено файлов: %d, пересобираю...\n", len(relevant))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
-		fmt.Println()
-		fmt.Println("⏹️  Сервер выключен")
-		os.Exit(0)
-	}()
+	cache.Invalidate(relevant...)
+	for _, path := range relevant {
+		if path == cfg.Path {
+			cache.Clear()
+			break
+		}
+	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("ошибка сервера: %w", err)
+	result, err := generateSpecs(cache)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
 	}
 
-	return nil
+	fmt.Printf("📄 Обновлено файлов: %d\n", len(result.Written)+len(result.Removed))
+	return len(result.Written)+len(result.Removed) > 0
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
 }
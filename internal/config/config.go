@@ -1,25 +1,268 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	DirName  = ".spec_agent"
+	FileName = "config.yaml"
+	EnvVar   = "SPEC_AGENT_CONFIG"
+)
+
+var ErrNotFound = errors.New("не найден " + DirName + "/" + FileName + " ни в текущем, ни в родительских каталогах")
+
 type Config struct {
-	Roots []string `yaml:"roots"`
+	Roots    []string       `yaml:"roots"`
+	Specs    SpecsConfig    `yaml:"specs"`
+	Export   ExportConfig   `yaml:"export"`
+	Sections SectionsConfig `yaml:"sections"`
+	Lint     LintConfig     `yaml:"lint"`
+	Rules    []RuleConfig   `yaml:"rules"`
+
+	Path string `yaml:"-"`
+	Root string `yaml:"-"`
+}
+
+type SpecsConfig struct {
+	Include         []string `yaml:"include"`
+	Exclude         []string `yaml:"exclude"`
+	DefaultExcludes *bool    `yaml:"default_excludes"`
+	Gitignore       *bool    `yaml:"gitignore"`
+}
+
+type ExportConfig struct {
+	Theme     string `yaml:"theme"`
+	Templates string `yaml:"templates"`
+}
+
+type SectionsConfig struct {
+	Language string              `yaml:"language"`
+	Aliases  map[string][]string `yaml:"aliases"`
+}
+
+type LintConfig struct {
+	Language   LanguageConfig `yaml:"language"`
+	Disable    []string       `yaml:"disable"`
+	HTTPLayers []string       `yaml:"http_layers"`
+}
+
+type RuleConfig struct {
+	Name    string        `yaml:"name"`
+	Command string        `yaml:"command"`
+	Args    []string      `yaml:"args"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type LanguageConfig struct {
+	Require  string  `yaml:"require"`
+	MinRatio float64 `yaml:"min_ratio"`
+	MinWords int     `yaml:"min_words"`
+}
+
+var unknownFieldRe = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(EnvVar)
+	}
+
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		found, err := Discover(wd)
+		if err != nil {
+			return nil, err
+		}
+		path = found
+	}
+
+	return LoadFile(path)
+}
+
+func Discover(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, DirName, FileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
 }
 
-func Load() (*Config, error) {
-	data, err := os.ReadFile(".spec_agent/config.yaml")
+func LoadFile(path string) (*Config, error) {
+	abs, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	data, err := os.ReadFile(abs)
+	if err != nil {
 		return nil, err
 	}
 
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", abs, describeError(err))
+	}
+
+	cfg.Path = abs
+	cfg.Root = filepath.Dir(abs)
+	if filepath.Base(cfg.Root) == DirName {
+		cfg.Root = filepath.Dir(cfg.Root)
+	}
+
+	for i, root := range cfg.Roots {
+		cfg.Roots[i] = cfg.Resolve(root)
+	}
+	if cfg.Export.Templates != "" {
+		cfg.Export.Templates = cfg.Resolve(cfg.Export.Templates)
+	}
+	for i, rule := range cfg.Rules {
+		if strings.ContainsRune(rule.Command, '/') {
+			cfg.Rules[i].Command = cfg.Resolve(rule.Command)
+		}
+	}
+
 	return &cfg, nil
 }
+
+func (c *Config) Resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(c.Root, path)
+}
+
+func (c *Config) BuildDir() string {
+	return filepath.Join(c.Root, DirName, "build")
+}
+
+func (c *Config) TemplatesDir() string {
+	if c.Export.Templates != "" {
+		return c.Export.Templates
+	}
+	return filepath.Join(c.Root, DirName, "templates")
+}
+
+func (c *Config) PromptsDir() string {
+	return filepath.Join(c.Root, DirName, "prompts")
+}
+
+func describeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	messages := make([]string, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		m := unknownFieldRe.FindStringSubmatch(msg)
+		if m == nil {
+			messages = append(messages, msg)
+			continue
+		}
+
+		line, _ := strconv.Atoi(m[1])
+		text := fmt.Sprintf("строка %d: неизвестный ключ «%s»", line, m[2])
+		if known := knownKeys(m[3]); len(known) > 0 {
+			if suggestion := closest(m[2], known); suggestion != "" {
+				text += fmt.Sprintf(", возможно имелся в виду «%s»", suggestion)
+			} else {
+				text += fmt.Sprintf(" (допустимо: %s)", strings.Join(known, ", "))
+			}
+		}
+		messages = append(messages, text)
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func knownKeys(typeName string) []string {
+	var keys []string
+	var walk func(t reflect.Type) bool
+	walk = func(t reflect.Type) bool {
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Ptr || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		if t.String() == typeName {
+			for i := 0; i < t.NumField(); i++ {
+				if tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; tag != "" && tag != "-" {
+					keys = append(keys, tag)
+				}
+			}
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if walk(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	}
+
+	walk(reflect.TypeOf(Config{}))
+	return keys
+}
+
+func closest(key string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := levenshtein(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
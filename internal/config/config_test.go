@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileUnknownKeySuggestions(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"верхний уровень", "rots:\n  - internal\n", "строка 1: неизвестный ключ «rots», возможно имелся в виду «roots»"},
+		{"вложенная секция", "lint:\n  disabel:\n    - language\n", "строка 2: неизвестный ключ «disabel», возможно имелся в виду «disable»"},
+		{"элемент rules", "rules:\n  - name: house\n    comand: ./rules.sh\n", "строка 3: неизвестный ключ «comand», возможно имелся в виду «command»"},
+		{"без похожего ключа", "rules:\n  - name: house\n    severity: error\n", "строка 3: неизвестный ключ «severity» (допустимо: name, command, args, timeout)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ошибка %v, ожидалось «%s»", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+(function () {
+  var ENDINGS = ["иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ая", "яя", "ое", "ее", "ые", "ие", "ый", "ий", "ой",
+    "ую", "юю", "ов", "ев", "ей", "ам", "ям", "ах", "ях", "ом", "ем", "ет", "ут", "ют", "ит", "ат", "ят", "ть", "а", "я", "о", "е",
+    "ы", "и", "у", "ю", "ь"];
+
+  function normalize(s) {
+    return (s || "").toLowerCase().replace(/ё/g, "е");
+  }
+
+  function tokenize(s) {
+    return normalize(s).split(/[^0-9a-zа-я_]+/).filter(function (t) { return t.length > 0; });
+  }
+
+  function stem(token) {
+    if (!/[а-я]/.test(token) || token.length <= 4) {
+      return token;
+    }
+    for (var i = 0; i < ENDINGS.length; i++) {
+      var e = ENDINGS[i];
+      if (token.length - e.length >= 3 && token.slice(-e.length) === e) {
+        return token.slice(0, -e.length);
+      }
+    }
+    return token;
+  }
+
+  var docs = (window.SPEC_SEARCH_INDEX || []).map(function (entry) {
+    var fields = [
+      { weight: 10, texts: [entry.t] },
+      { weight: 3, texts: entry.h || [] },
+      { weight: 2, texts: entry.r || [] },
+      { weight: 2, texts: entry.e || [] }
+    ];
+    return {
+      entry: entry,
+      fields: fields.map(function (f) {
+        return {
+          weight: f.weight,
+          texts: f.texts,
+          tokens: f.texts.map(tokenize)
+        };
+      })
+    };
+  });
+
+  function search(query) {
+    var terms = tokenize(query).map(stem);
+    if (terms.length === 0) {
+      return [];
+    }
+
+    var results = [];
+    docs.forEach(function (doc) {
+      var score = 0;
+      var snippet = "";
+      var matchedAll = terms.every(function (term) {
+        var found = false;
+        doc.fields.forEach(function (field) {
+          field.tokens.forEach(function (tokens, i) {
+            for (var k = 0; k < tokens.length; k++) {
+              if (tokens[k].indexOf(term) === 0) {
+                score += field.weight;
+                found = true;
+                if (!snippet && field.weight < 10) {
+                  snippet = field.texts[i];
+                }
+                break;
+              }
+            }
+          });
+        });
+        return found;
+      });
+      if (matchedAll) {
+        results.push({ entry: doc.entry, score: score, snippet: snippet });
+      }
+    });
+
+    results.sort(function (a, b) { return b.score - a.score; });
+    return results.slice(0, 20);
+  }
+
+  function render(list, results) {
+    list.innerHTML = "";
+    results.forEach(function (r) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = r.entry.u;
+      a.textContent = r.entry.t;
+      if (r.snippet) {
+        var small = document.createElement("small");
+        small.textContent = r.snippet;
+        a.appendChild(small);
+      }
+      li.appendChild(a);
+      list.appendChild(li);
+    });
+  }
+
+  document.addEventListener("DOMContentLoaded", function () {
+    var input = document.getElementById("spec-search");
+    var list = document.getElementById("spec-search-results");
+    if (!input || !list) {
+      return;
+    }
+
+    input.addEventListener("input", function () {
+      render(list, search(input.value));
+    });
+
+    input.addEventListener("keydown", function (e) {
+      if (e.key === "Enter") {
+        var first = list.querySelector("a");
+        if (first) {
+          window.location.href = first.getAttribute("href");
+        }
+      }
+      if (e.key === "Escape") {
+        input.value = "";
+        list.innerHTML = "";
+      }
+    });
+  });
+})();
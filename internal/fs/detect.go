@@ -0,0 +1,83 @@
+package fs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var LayerDirs = []string{
+	"controllers", "controller", "handlers", "handler", "transport",
+	"usecases", "usecase", "services", "service",
+	"repositories", "repository", "middleware",
+}
+
+var FallbackRoots = []string{"internal/controllers", "cmd"}
+
+func FindModuleRoot(start string) (string, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return start, false
+	}
+
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start, false
+		}
+		dir = parent
+	}
+}
+
+func DetectRoots(dir string) []string {
+	layers := map[string]bool{}
+	for _, name := range LayerDirs {
+		layers[name] = true
+	}
+
+	var roots []string
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == dir {
+			return nil
+		}
+
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+			name == "vendor" || name == "node_modules" || name == "testdata" || name == "spec_changes" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			return filepath.SkipDir
+		}
+
+		if layers[name] {
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				roots = append(roots, filepath.ToSlash(rel))
+			}
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	if len(roots) > 0 {
+		sort.Strings(roots)
+		return roots
+	}
+
+	for _, root := range FallbackRoots {
+		if info, err := os.Stat(filepath.Join(dir, root)); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		}
+	}
+	if len(roots) == 0 {
+		roots = FallbackRoots
+	}
+
+	return roots
+}
@@ -1,54 +1,165 @@
 package fs
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 )
 
 //go:embed assets
 var embeddedAssets embed.FS
 
-func InitSpecAgent() error {
-	if err := os.MkdirAll(".spec_agent", 0755); err != nil {
-		return err
-	}
-	if err := os.MkdirAll("spec_changes", 0755); err != nil {
-		return err
+const (
+	specAgentDir        = ".spec_agent"
+	configFile          = "config.yaml"
+	assetsFile          = "assets.json"
+	templatesExampleDir = "templates.example"
+)
+
+type InitOptions struct {
+	Dir     string
+	Roots   []string
+	Force   bool
+	Upgrade bool
+}
+
+type InitResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Modified  []string
+	Removed   []string
+}
+
+type assetsManifest struct {
+	Files map[string]string `json:"files"`
+}
+
+type initFile struct {
+	path  string
+	asset string
+	data  []byte
+}
+
+func InitSpecAgent(opts InitOptions) (*InitResult, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
 	}
 
-	config := `roots:
-  - internal/controllers
-  - cmd
-`
+	manifestPath := filepath.Join(dir, specAgentDir, assetsFile)
+	previous := readAssetsManifest(manifestPath)
 
-	if err := os.WriteFile(".spec_agent/config.yaml", []byte(config), 0644); err != nil {
-		return err
+	files, err := initFiles(opts.Roots)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := copyAssetsToSpecAgent(); err != nil {
-		return err
+	if !opts.Force && !opts.Upgrade {
+		if conflicts := initConflicts(dir, files); len(conflicts) > 0 {
+			return nil, fmt.Errorf("spec-agent уже инициализирован, изменённые файлы не будут перезаписаны: %s (используйте --upgrade или --force)", strings.Join(conflicts, ", "))
+		}
 	}
 
-	return nil
-}
+	result := &InitResult{}
+	current := assetsManifest{Files: map[string]string{}}
 
-func copyAssetsToSpecAgent() error {
-	return fs.WalkDir(embeddedAssets, "assets", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	for _, f := range files {
+		dest := filepath.Join(dir, filepath.FromSlash(f.path))
+		hash := hashBytes(f.data)
+
+		existing, err := os.ReadFile(dest)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if err := writeInitFile(dest, f.data); err != nil {
+				return nil, err
+			}
+			result.Created = append(result.Created, f.path)
+		case err != nil:
+			return nil, err
+		case bytes.Equal(existing, f.data):
+			result.Unchanged = append(result.Unchanged, f.path)
+		case opts.Force:
+			if err := writeInitFile(dest, f.data); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, f.path)
+		case f.asset != "" && previous.Files[f.asset] == hashBytes(existing):
+			if err := writeInitFile(dest, f.data); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, f.path)
+		default:
+			result.Modified = append(result.Modified, f.path)
+			if f.asset != "" && previous.Files[f.asset] != "" {
+				hash = previous.Files[f.asset]
+			} else {
+				hash = ""
+			}
+		}
+
+		if f.asset != "" && hash != "" {
+			current.Files[f.asset] = hash
 		}
+	}
 
-		if path == "assets" {
-			return nil
+	for asset, hash := range previous.Files {
+		if slices.ContainsFunc(files, func(f initFile) bool { return f.asset == asset }) {
+			continue
+		}
+		removed, err := removeStaleAsset(dir, asset, hash)
+		if err != nil {
+			return nil, err
 		}
+		if removed {
+			result.Removed = append(result.Removed, specAgentDir+"/"+asset)
+		}
+	}
+	sort.Strings(result.Removed)
+
+	if err := os.MkdirAll(filepath.Join(dir, "spec_changes"), 0755); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("не удалось записать %s: %w", assetsFile, err)
+	}
+
+	return result, nil
+}
 
-		relPath := path[len("assets/"):]
-		destPath := filepath.Join(".spec_agent", relPath)
+func ConfigTemplate(roots []string) string {
+	var b strings.Builder
+	b.WriteString("roots:\n")
+	for _, root := range roots {
+		fmt.Fprintf(&b, "  - %s\n", root)
+	}
+	return b.String()
+}
 
-		if d.IsDir() {
-			return os.MkdirAll(destPath, 0755)
+func initFiles(roots []string) ([]initFile, error) {
+	files := []initFile{{
+		path: specAgentDir + "/" + configFile,
+		data: []byte(ConfigTemplate(roots)),
+	}}
+
+	err := fs.WalkDir(embeddedAssets, "assets", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
 		}
 
 		data, err := embeddedAssets.ReadFile(path)
@@ -56,10 +167,78 @@ func copyAssetsToSpecAgent() error {
 			return err
 		}
 
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return err
+		asset := strings.TrimPrefix(path, "assets/")
+		if rest, ok := strings.CutPrefix(asset, "templates/"); ok {
+			asset = templatesExampleDir + "/" + rest
 		}
+		files = append(files, initFile{path: specAgentDir + "/" + asset, asset: asset, data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		return os.WriteFile(destPath, data, 0644)
+	sort.SliceStable(files[1:], func(i, j int) bool {
+		return files[i+1].path < files[j+1].path
 	})
+
+	return files, nil
+}
+
+func initConflicts(dir string, files []initFile) []string {
+	var conflicts []string
+	for _, f := range files {
+		existing, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.path)))
+		if err == nil && !bytes.Equal(existing, f.data) {
+			conflicts = append(conflicts, f.path)
+		}
+	}
+	return conflicts
+}
+
+func removeStaleAsset(dir, asset, hash string) (bool, error) {
+	if !filepath.IsLocal(filepath.FromSlash(asset)) {
+		return false, nil
+	}
+
+	base := filepath.Join(dir, specAgentDir)
+	dest := filepath.Join(base, filepath.FromSlash(asset))
+
+	existing, err := os.ReadFile(dest)
+	if err != nil || hashBytes(existing) != hash {
+		return false, nil
+	}
+	if err := os.Remove(dest); err != nil {
+		return false, fmt.Errorf("не удалось удалить %s: %w", specAgentDir+"/"+asset, err)
+	}
+
+	for parent := filepath.Dir(dest); parent != base; parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return true, nil
+}
+
+func writeInitFile(dest string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
+
+func readAssetsManifest(path string) assetsManifest {
+	manifest := assetsManifest{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &manifest)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]string{}
+	}
+	return manifest
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+const PromptsDir = ".spec_agent/prompts"
+
+const legacyRulesPlaceholder = "{{SPEC_RULES_SOURCE}}"
+
+var DefaultLayers = []string{"controllers", "usecases", "services", "repositories", "models", "middleware"}
+
+type PromptVars struct {
+	SpecRulesSource string
+	SpecRules       string
+	Layers          []string
+	Roots           []string
+	Language        string
+	LanguageCode    string
+	Date            string
+	PlanPrefix      string
+}
+
+func Prompts(overrideDir string) fs.FS {
+	embedded, _ := fs.Sub(embeddedAssets, "assets/prompts")
+	return &templatesFS{overrideDir: overrideDir, embedded: embedded}
+}
+
+func RenderPrompt(prompts fs.FS, name string, vars PromptVars) (string, error) {
+	data, err := fs.ReadFile(prompts, name)
+	if err != nil {
+		return "", err
+	}
+
+	source := strings.ReplaceAll(string(data), legacyRulesPlaceholder, "{{.SpecRulesSource}}")
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("ошибка в шаблоне промта %s: %w", name, err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("не удалось отрисовать промт %s: %w", name, err)
+	}
+
+	return out.String(), nil
+}
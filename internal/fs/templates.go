@@ -0,0 +1,38 @@
+package fs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const TemplatesDir = ".spec_agent/templates"
+
+type templatesFS struct {
+	overrideDir string
+	embedded    fs.FS
+}
+
+func Templates(overrideDir string) fs.FS {
+	embedded, _ := fs.Sub(embeddedAssets, "assets/templates")
+	return &templatesFS{overrideDir: overrideDir, embedded: embedded}
+}
+
+func (t *templatesFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if t.overrideDir != "" {
+		f, err := os.Open(filepath.Join(t.overrideDir, filepath.FromSlash(name)))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return t.embedded.Open(name)
+}
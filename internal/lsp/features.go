@@ -0,0 +1,408 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+var completionRefRe = regexp.MustCompile(`(?:→|->)\s*(?:uses|reads|writes|calls|validates):\s*(\S*)$`)
+
+type linkTarget struct {
+	Path   string
+	Anchor string
+	Range  spec.Range
+}
+
+func (s *Server) document(uri string) (string, string, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	content, ok := s.documents[path]
+	s.mu.Unlock()
+	if ok {
+		return path, content, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	return path, string(data), nil
+}
+
+func (s *Server) readSpec(path string) (*spec.Spec, error) {
+	_, content, err := s.document(pathToURI(path))
+	if err != nil {
+		return nil, err
+	}
+	return s.parse(path, content), nil
+}
+
+func (s *Server) parse(path, content string) *spec.Spec {
+	var names *spec.SectionNames
+	if project := s.loadProject(); project != nil {
+		names = project.Cache.SectionNames()
+	}
+	return spec.ParseContentWith(path, content, names)
+}
+
+func (s *Server) loadProject() *spec.Project {
+	s.mu.Lock()
+	project := s.project
+	s.mu.Unlock()
+	if project != nil {
+		return project
+	}
+
+	if s.opts.Load == nil {
+		return nil
+	}
+	project, err := s.opts.Load()
+	if err != nil {
+		s.opts.Logger.Printf("не удалось загрузить проект: %v", err)
+		return nil
+	}
+
+	s.mu.Lock()
+	s.project = project
+	s.mu.Unlock()
+	return project
+}
+
+func (s *Server) invalidateProject() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.project = nil
+}
+
+func (s *Server) publishDiagnostics(uri string) {
+	path, content, err := s.document(uri)
+	if err != nil || filepath.Ext(path) != ".md" {
+		return
+	}
+
+	opts := spec.LintOptions{}
+	if project := s.loadProject(); project != nil {
+		opts = project.Lint
+	}
+
+	diagnostics := []diagnostic{}
+	for _, d := range spec.LintSpec(s.parse(path, content), opts) {
+		r := d.Range()
+		if d.End.Line == 0 {
+			r.End = spec.Position{Line: r.Start.Line, Column: len([]rune(lineText(content, r.Start.Line-1))) + 1}
+		}
+
+		severity := severityInfo
+		switch d.Severity {
+		case spec.SeverityError:
+			severity = severityError
+		case spec.SeverityWarning:
+			severity = severityWarning
+		}
+
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    toLSPRange(content, r),
+			Severity: severity,
+			Code:     d.RuleID,
+			Source:   "spec-agent",
+			Message:  d.Message,
+		})
+	}
+
+	s.notify("textDocument/publishDiagnostics", map[string]any{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+func (s *Server) definition(params textDocumentPosition) (any, *rpcError) {
+	path, content, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil
+	}
+
+	target, ok := targetAt(s.parse(path, content), fromLSP(content, params.Position))
+	if !ok {
+		return nil, nil
+	}
+
+	_, targetContent, err := s.document(pathToURI(target.Path))
+	if err != nil {
+		return nil, nil
+	}
+
+	r := anchorRange(s.parse(target.Path, targetContent), target.Anchor)
+	return Location{URI: pathToURI(target.Path), Range: toLSPRange(targetContent, r)}, nil
+}
+
+func (s *Server) references(params textDocumentPosition, includeDeclaration bool) (any, *rpcError) {
+	path, content, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil
+	}
+
+	target := path
+	anchor := ""
+	if t, ok := targetAt(s.parse(path, content), fromLSP(content, params.Position)); ok {
+		target, anchor = t.Path, t.Anchor
+	}
+
+	locations := []Location{}
+
+	if includeDeclaration {
+		if ts, err := s.readSpec(target); err == nil {
+			_, targetContent, _ := s.document(pathToURI(target))
+			locations = append(locations, Location{
+				URI:   pathToURI(target),
+				Range: toLSPRange(targetContent, anchorRange(ts, anchor)),
+			})
+		}
+	}
+
+	project := s.loadProject()
+	if project == nil {
+		return locations, nil
+	}
+
+	for _, source := range project.Paths() {
+		if source == target {
+			continue
+		}
+		_, sourceContent, err := s.document(pathToURI(source))
+		if err != nil {
+			continue
+		}
+		for _, t := range linkTargets(s.parse(source, sourceContent)) {
+			if t.Path != target || (anchor != "" && t.Anchor != anchor) {
+				continue
+			}
+			locations = append(locations, Location{
+				URI:   pathToURI(source),
+				Range: toLSPRange(sourceContent, t.Range),
+			})
+		}
+	}
+
+	sort.SliceStable(locations, func(i, j int) bool {
+		if locations[i].URI != locations[j].URI {
+			return locations[i].URI < locations[j].URI
+		}
+		return locations[i].Range.Start.Line < locations[j].Range.Start.Line
+	})
+
+	return locations, nil
+}
+
+func (s *Server) completion(params textDocumentPosition) (any, *rpcError) {
+	path, content, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return []completionItem{}, nil
+	}
+
+	line := lineText(content, params.Position.Line)
+	pos := fromLSP(content, params.Position)
+	runes := []rune(line)
+	prefix := string(runes[:min(pos.Column-1, len(runes))])
+
+	m := completionRefRe.FindStringSubmatch(prefix)
+	if m == nil {
+		return []completionItem{}, nil
+	}
+	partial := m[1]
+
+	editStart := spec.Position{Line: pos.Line, Column: pos.Column - len([]rune(partial))}
+	dir := filepath.Dir(path)
+	items := []completionItem{}
+
+	if hash := strings.Index(partial, "#"); hash >= 0 {
+		targetRel := partial[:hash]
+		target, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(targetRel)))
+		if err != nil {
+			return items, nil
+		}
+		for _, a := range s.anchors(target) {
+			items = append(items, completionItem{
+				Label:  a,
+				Kind:   completionMethod,
+				Detail: targetRel,
+				TextEdit: &textEdit{
+					Range:   toLSPRange(content, spec.Range{Start: editStart, End: pos}),
+					NewText: targetRel + "#" + a,
+				},
+			})
+		}
+		return items, nil
+	}
+
+	project := s.loadProject()
+	if project == nil {
+		return items, nil
+	}
+
+	for _, candidate := range project.Files {
+		abs, err := filepath.Abs(candidate)
+		if err != nil || abs == path {
+			continue
+		}
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		item := completionItem{
+			Label: rel,
+			Kind:  completionFile,
+			TextEdit: &textEdit{
+				Range:   toLSPRange(content, spec.Range{Start: editStart, End: pos}),
+				NewText: rel,
+			},
+		}
+		if cs, err := project.Cache.ParseFile(abs); err == nil {
+			item.Detail = cs.Title
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items, nil
+}
+
+func (s *Server) hover(params textDocumentPosition) (any, *rpcError) {
+	path, content, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil
+	}
+
+	target, ok := targetAt(s.parse(path, content), fromLSP(content, params.Position))
+	if !ok {
+		return nil, nil
+	}
+
+	ts, err := s.readSpec(target.Path)
+	if err != nil {
+		return hover{Contents: markupContent{Kind: "markdown", Value: fmt.Sprintf("⚠️ Спецификация не найдена: `%s`", spec.RelPath(target.Path))}}, nil
+	}
+
+	var b strings.Builder
+	title := ts.Title
+	if title == "" {
+		title = filepath.Base(target.Path)
+	}
+	fmt.Fprintf(&b, "**%s**", title)
+	if target.Anchor != "" {
+		fmt.Fprintf(&b, " → `%s`", target.Anchor)
+	}
+	b.WriteString("\n\n")
+	if section := ts.Section(spec.SectionResponsibility); section != nil {
+		b.WriteString(strings.TrimSpace(section.Body))
+	} else {
+		b.WriteString("_Секция Responsibility не заполнена_")
+	}
+
+	r := toLSPRange(content, target.Range)
+	return hover{Contents: markupContent{Kind: "markdown", Value: b.String()}, Range: &r}, nil
+}
+
+func (s *Server) anchors(target string) []string {
+	seen := map[string]bool{}
+	var result []string
+	add := func(a string) {
+		if a != "" && !seen[a] {
+			seen[a] = true
+			result = append(result, a)
+		}
+	}
+
+	if ts, err := s.readSpec(target); err == nil {
+		for _, h := range ts.Headings {
+			if h.Level >= 3 {
+				add(h.Text)
+			}
+		}
+	}
+
+	if project := s.loadProject(); project != nil {
+		for _, source := range project.Paths() {
+			ss, err := project.Cache.ParseFile(source)
+			if err != nil {
+				continue
+			}
+			for _, t := range linkTargets(ss) {
+				if t.Path == target {
+					add(t.Anchor)
+				}
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func linkTargets(s *spec.Spec) []linkTarget {
+	dir := filepath.Dir(s.Path)
+	var targets []linkTarget
+
+	for _, link := range s.Links {
+		if path, err := filepath.Abs(filepath.Join(dir, link.Path)); err == nil {
+			targets = append(targets, linkTarget{Path: path, Range: link.Range})
+		}
+	}
+	for _, ref := range s.Refs {
+		if path, err := filepath.Abs(filepath.Join(dir, ref.Path)); err == nil {
+			targets = append(targets, linkTarget{Path: path, Anchor: ref.Anchor, Range: ref.Range})
+		}
+	}
+
+	return targets
+}
+
+func targetAt(s *spec.Spec, pos spec.Position) (linkTarget, bool) {
+	for _, t := range linkTargets(s) {
+		if contains(t.Range, pos) {
+			return t, true
+		}
+	}
+	return linkTarget{}, false
+}
+
+func anchorRange(s *spec.Spec, anchor string) spec.Range {
+	top := spec.Range{Start: spec.Position{Line: 1, Column: 1}, End: spec.Position{Line: 1, Column: 1}}
+	if s.Title != "" {
+		top = s.TitleRange
+	}
+	if anchor == "" {
+		return top
+	}
+
+	for _, h := range s.Headings {
+		if strings.EqualFold(h.Text, anchor) {
+			return h.Range
+		}
+	}
+
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(anchor) + `\b`)
+	for i, line := range strings.Split(s.Content, "\n") {
+		if loc := word.FindStringIndex(line); loc != nil {
+			start := len([]rune(line[:loc[0]])) + 1
+			end := len([]rune(line[:loc[1]])) + 1
+			return spec.Range{
+				Start: spec.Position{Line: i + 1, Column: start},
+				End:   spec.Position{Line: i + 1, Column: end},
+			}
+		}
+	}
+
+	return top
+}
@@ -0,0 +1,102 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+func TestMessageFraming(t *testing.T) {
+	var buf bytes.Buffer
+	first := message{ID: json.RawMessage("1"), Method: "textDocument/hover", Params: json.RawMessage(`{"text":"Привет 🚀"}`)}
+	second := message{ID: json.RawMessage("2"), Result: "ок"}
+	for _, msg := range []message{first, second} {
+		if err := writeMessage(&buf, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	header, _, _ := strings.Cut(buf.String(), "\r\n\r\n")
+	body, _ := json.Marshal(message{JSONRPC: "2.0", ID: first.ID, Method: first.Method, Params: first.Params})
+	if want := "Content-Length: " + strconv.Itoa(len(body)); header != want {
+		t.Errorf("заголовок %q, ожидался %q (длина в байтах, не в символах)", header, want)
+	}
+
+	reader := bufio.NewReader(&buf)
+	for _, want := range []message{first, second} {
+		data, err := readMessage(reader)
+		if err != nil {
+			t.Fatalf("readMessage: %v", err)
+		}
+		var got message
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("тело %q не JSON: %v", data, err)
+		}
+		if got.JSONRPC != "2.0" || string(got.ID) != string(want.ID) || got.Method != want.Method || string(got.Params) != string(want.Params) {
+			t.Errorf("получено %+v, ожидалось %+v", got, want)
+		}
+	}
+
+	if _, err := readMessage(reader); !errors.Is(err, io.EOF) {
+		t.Errorf("после последнего сообщения ожидался EOF, получено %v", err)
+	}
+}
+
+func TestReadMessageBadHeader(t *testing.T) {
+	for _, input := range []string{
+		"Content-Type: application/json\r\n\r\n{}",
+		"Content-Length: abc\r\n\r\n{}",
+		"Content-Length: -1\r\n\r\n{}",
+	} {
+		if _, err := readMessage(bufio.NewReader(strings.NewReader(input))); err == nil || !strings.Contains(err.Error(), "Content-Length") {
+			t.Errorf("%q: ожидалась ошибка Content-Length, получено %v", input, err)
+		}
+	}
+
+	if _, err := readMessage(bufio.NewReader(strings.NewReader("Content-Length: 10\r\n\r\n{}"))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("обрезанное тело: ожидался io.ErrUnexpectedEOF, получено %v", err)
+	}
+}
+
+func TestPositionConversion(t *testing.T) {
+	content := "# Заголовок\nПривет 🚀 мир 𝔘\r\nascii\n"
+
+	tests := []struct {
+		pos  spec.Position
+		want Position
+	}{
+		{spec.Position{Line: 1, Column: 1}, Position{Line: 0, Character: 0}},
+		{spec.Position{Line: 1, Column: 3}, Position{Line: 0, Character: 2}},
+		{spec.Position{Line: 2, Column: 8}, Position{Line: 1, Character: 7}},
+		{spec.Position{Line: 2, Column: 9}, Position{Line: 1, Character: 9}},
+		{spec.Position{Line: 2, Column: 10}, Position{Line: 1, Character: 10}},
+		{spec.Position{Line: 2, Column: 14}, Position{Line: 1, Character: 14}},
+		{spec.Position{Line: 2, Column: 15}, Position{Line: 1, Character: 16}},
+		{spec.Position{Line: 3, Column: 6}, Position{Line: 2, Character: 5}},
+	}
+	for _, tt := range tests {
+		got := toLSP(content, tt.pos)
+		if got != tt.want {
+			t.Errorf("toLSP(%+v) = %+v, ожидалось %+v", tt.pos, got, tt.want)
+		}
+		if back := fromLSP(content, got); back != tt.pos {
+			t.Errorf("fromLSP(%+v) = %+v, ожидалось %+v", got, back, tt.pos)
+		}
+	}
+
+	r := toLSPRange(content, spec.Range{Start: spec.Position{Line: 2, Column: 8}, End: spec.Position{Line: 2, Column: 40}})
+	if want := (Range{Start: Position{Line: 1, Character: 7}, End: Position{Line: 1, Character: 16}}); r != want {
+		t.Errorf("toLSPRange = %+v, ожидалось %+v (конец ограничен длиной строки без \\r)", r, want)
+	}
+
+	if got := fromLSP(content, Position{Line: 1, Character: 8}); got != (spec.Position{Line: 2, Column: 9}) {
+		t.Errorf("позиция внутри суррогатной пары: %+v, ожидалась позиция после эмодзи", got)
+	}
+}
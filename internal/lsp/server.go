@@ -0,0 +1,238 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+type ProjectLoader = spec.ProjectLoader
+
+type Options struct {
+	Load    ProjectLoader
+	Version string
+	Logger  *log.Logger
+}
+
+type Server struct {
+	opts Options
+
+	mu        sync.Mutex
+	out       io.Writer
+	documents map[string]string
+	project   *spec.Project
+}
+
+func NewServer(opts Options) *Server {
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+	return &Server{opts: opts, documents: map[string]string{}}
+}
+
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		body, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.send(message{ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		if msg.Method == "" {
+			continue
+		}
+
+		result, rpcErr := s.dispatch(msg)
+		if msg.ID == nil {
+			continue
+		}
+		if rpcErr != nil {
+			s.send(message{ID: msg.ID, Error: rpcErr})
+			continue
+		}
+		if result == nil {
+			result = json.RawMessage("null")
+		}
+		s.send(message{ID: msg.ID, Result: result})
+	}
+}
+
+func (s *Server) dispatch(msg message) (any, *rpcError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    textDocumentSyncFull,
+					"save":      map[string]any{"includeText": false},
+				},
+				"definitionProvider": true,
+				"referencesProvider": true,
+				"hoverProvider":      true,
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{":", "/", "#", " "},
+				},
+			},
+			"serverInfo": map[string]any{"name": "spec-agent", "version": s.opts.Version},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.setDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.publishDiagnostics(params.TextDocument.URI)
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.setDocument(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didSave":
+		var params textDocumentPosition
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.invalidateProject()
+		s.publishDiagnostics(params.TextDocument.URI)
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params textDocumentPosition
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if path, err := uriToPath(params.TextDocument.URI); err == nil {
+			s.mu.Lock()
+			delete(s.documents, path)
+			s.mu.Unlock()
+		}
+		s.notify("textDocument/publishDiagnostics", map[string]any{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []diagnostic{},
+		})
+		return nil, nil
+
+	case "workspace/didChangeWatchedFiles":
+		s.invalidateProject()
+		return nil, nil
+
+	case "textDocument/definition":
+		return s.withPosition(msg.Params, s.definition)
+
+	case "textDocument/references":
+		var params struct {
+			textDocumentPosition
+			Context struct {
+				IncludeDeclaration bool `json:"includeDeclaration"`
+			} `json:"context"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.references(params.textDocumentPosition, params.Context.IncludeDeclaration)
+
+	case "textDocument/completion":
+		return s.withPosition(msg.Params, s.completion)
+
+	case "textDocument/hover":
+		return s.withPosition(msg.Params, s.hover)
+
+	default:
+		if msg.ID == nil {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "метод не поддерживается: " + msg.Method}
+	}
+}
+
+func (s *Server) withPosition(raw json.RawMessage, handler func(textDocumentPosition) (any, *rpcError)) (any, *rpcError) {
+	var params textDocumentPosition
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, invalidParams(err)
+	}
+	return handler(params)
+}
+
+func (s *Server) setDocument(uri, text string) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		s.opts.Logger.Printf("%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[path] = text
+}
+
+func (s *Server) send(msg message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeMessage(s.out, msg); err != nil {
+		s.opts.Logger.Printf("не удалось отправить сообщение: %v", err)
+	}
+}
+
+func (s *Server) notify(method string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		s.opts.Logger.Printf("не удалось закодировать %s: %v", method, err)
+		return
+	}
+	s.send(message{Method: method, Params: data})
+}
+
+func invalidParams(err error) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+}
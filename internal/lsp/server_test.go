@@ -0,0 +1,203 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+const apiSpec = "# API\n\n## Responsibility\nПринимает 🚀 запросы.\n\n## Dependencies\n- 🚀 [Service](service.md)\n\n## Flow\n1. Вызывает сервис\n   → calls: service.md#Do\n   → uses: \n   → calls: service.md#\n"
+
+const serviceSpec = "# Service\n\n## Responsibility\nДелает работу.\n\n### Do\nВыполняет операцию.\n"
+
+func writeSpec(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type session struct {
+	responses     map[string]message
+	notifications []message
+}
+
+func runSession(t *testing.T, s *Server, requests ...string) session {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, r := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), &in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	result := session{responses: map[string]message{}}
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("некорректный кадр в выводе: %v", err)
+		}
+		var msg struct {
+			message
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("некорректный ответ %q: %v", body, err)
+		}
+		msg.message.Result = msg.Result
+		if msg.Method != "" {
+			result.notifications = append(result.notifications, msg.message)
+			continue
+		}
+		result.responses[string(msg.ID)] = msg.message
+	}
+	return result
+}
+
+func (s session) result(t *testing.T, id string, v any) {
+	t.Helper()
+	resp, ok := s.responses[id]
+	if !ok {
+		t.Fatalf("нет ответа на запрос %s", id)
+	}
+	if resp.Error != nil {
+		t.Fatalf("запрос %s: ошибка JSON-RPC: %s", id, resp.Error.Message)
+	}
+	if err := json.Unmarshal(resp.Result.(json.RawMessage), v); err != nil {
+		t.Fatalf("запрос %s: %v", id, err)
+	}
+}
+
+func TestStdioSession(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "specs", "api.md")
+	service := filepath.Join(root, "specs", "service.md")
+	writeSpec(t, api, apiSpec)
+	writeSpec(t, service, serviceSpec)
+
+	load := func() (*spec.Project, error) {
+		return spec.LoadProject([]string{filepath.Join(root, "specs")}, spec.DiscoverOptions{Root: root}, nil)
+	}
+	s := NewServer(Options{Load: load, Version: "test"})
+
+	apiURI, serviceURI := pathToURI(api), pathToURI(service)
+	open, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": apiURI, "languageId": "markdown", "version": 1, "text": apiSpec}})
+	position := func(id, method, uri string, line, character int, extra string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"method":%q,"params":{"textDocument":{"uri":%q},"position":{"line":%d,"character":%d}%s}}`, id, method, uri, line, character, extra)
+	}
+
+	sess := runSession(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(open)+`}`,
+		position("2", "textDocument/definition", apiURI, 6, 6, ""),
+		position("3", "textDocument/hover", apiURI, 6, 6, ""),
+		position("4", "textDocument/references", serviceURI, 0, 0, `,"context":{"includeDeclaration":true}`),
+		position("5", "textDocument/completion", apiURI, 11, 11, ""),
+		position("6", "textDocument/completion", apiURI, 12, 23, ""),
+		position("7", "textDocument/definition", apiURI, 10, 17, ""),
+		`{"jsonrpc":"2.0","id":8,"method":"unknown/method"}`,
+		`{"jsonrpc":"2.0","id":9,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	var init struct {
+		Capabilities map[string]any `json:"capabilities"`
+		ServerInfo   struct {
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	sess.result(t, "1", &init)
+	if init.ServerInfo.Version != "test" || init.Capabilities["definitionProvider"] != true {
+		t.Errorf("неожиданный ответ initialize: %+v", init)
+	}
+
+	if len(sess.notifications) != 1 || sess.notifications[0].Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("после didOpen ожидалась одна publishDiagnostics, получено %+v", sess.notifications)
+	}
+	var published struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(sess.notifications[0].Params, &published); err != nil || published.URI != apiURI {
+		t.Errorf("publishDiagnostics для %q, ожидался %q (%v)", published.URI, apiURI, err)
+	}
+
+	var definition Location
+	sess.result(t, "2", &definition)
+	if definition.URI != serviceURI || definition.Range.Start.Line != 0 {
+		t.Errorf("definition = %+v, ожидался заголовок %s", definition, serviceURI)
+	}
+
+	var h hover
+	sess.result(t, "3", &h)
+	if !strings.Contains(h.Contents.Value, "**Service**") || !strings.Contains(h.Contents.Value, "Делает работу.") {
+		t.Errorf("hover = %q", h.Contents.Value)
+	}
+	if h.Range == nil || h.Range.Start != (Position{Line: 6, Character: 5}) {
+		t.Errorf("hover range = %+v, ожидалось начало ссылки в UTF-16 {6 5}", h.Range)
+	}
+
+	var references []Location
+	sess.result(t, "4", &references)
+	if len(references) != 4 {
+		t.Fatalf("ожидалось 4 ссылки (объявление, Dependencies, два шага Flow), получено %+v", references)
+	}
+	lines := []int{}
+	for _, ref := range references {
+		if ref.URI == apiURI {
+			lines = append(lines, ref.Range.Start.Line)
+		}
+	}
+	if len(lines) != 3 || lines[0] != 6 || lines[1] != 10 || lines[2] != 12 {
+		t.Errorf("ссылки из api.md на строках %v, ожидалось [6 10 12]", lines)
+	}
+
+	var files []completionItem
+	sess.result(t, "5", &files)
+	if len(files) != 1 || files[0].Label != "service.md" || files[0].Detail != "Service" {
+		t.Fatalf("completion файлов = %+v", files)
+	}
+	if files[0].TextEdit == nil || files[0].TextEdit.Range.Start != (Position{Line: 11, Character: 11}) {
+		t.Errorf("textEdit = %+v, ожидалась вставка в позиции курсора", files[0].TextEdit)
+	}
+
+	var anchors []completionItem
+	sess.result(t, "6", &anchors)
+	if len(anchors) != 1 || anchors[0].Label != "Do" || anchors[0].TextEdit.NewText != "service.md#Do" {
+		t.Errorf("completion якорей = %+v", anchors)
+	}
+
+	var anchor Location
+	sess.result(t, "7", &anchor)
+	if anchor.URI != serviceURI || anchor.Range.Start.Line != 5 {
+		t.Errorf("definition якоря = %+v, ожидался заголовок ### Do", anchor)
+	}
+
+	if resp := sess.responses["8"]; resp.Error == nil || resp.Error.Code != codeMethodNotFound {
+		t.Errorf("неизвестный метод: %+v", resp)
+	}
+	if _, ok := sess.responses["9"]; !ok {
+		t.Error("нет ответа на shutdown")
+	}
+}
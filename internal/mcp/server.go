@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+const latestProtocolVersion = "2025-06-18"
+
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type ProjectLoader = spec.ProjectLoader
+
+type Options struct {
+	Load    ProjectLoader
+	Root    string
+	Version string
+}
+
+type Server struct {
+	opts  Options
+	tools []tool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+func NewServer(opts Options) *Server {
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+	s := &Server{opts: opts}
+	s.tools = s.registerTools()
+	return s
+}
+
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			s.handle(line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.reply(nil, nil, &rpcError{Code: codeParseError, Message: "некорректный JSON: " + err.Error()})
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.ID != nil {
+			s.reply(req.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "ожидается запрос JSON-RPC 2.0"})
+		}
+		return
+	}
+
+	result, rpcErr := s.dispatch(req)
+	if req.ID == nil {
+		return
+	}
+	s.reply(req.ID, result, rpcErr)
+}
+
+func (s *Server) dispatch(req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+
+		version := latestProtocolVersion
+		if slices.Contains(supportedProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "spec-agent", "version": s.opts.Version},
+			"instructions":    "Specifications are the source of truth. Start from the entry spec (get_spec, spec_tree), check impact, record a change plan (create_change_plan), update specs, then code, and run lint.",
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		list := make([]map[string]any, 0, len(s.tools))
+		for _, t := range s.tools {
+			list = append(list, map[string]any{
+				"name":        t.name,
+				"description": t.description,
+				"inputSchema": t.schema,
+			})
+		}
+		return map[string]any{"tools": list}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		idx := slices.IndexFunc(s.tools, func(t tool) bool { return t.name == params.Name })
+		if idx < 0 {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "неизвестный инструмент: " + params.Name}
+		}
+
+		return s.call(s.tools[idx], params.Arguments), nil
+
+	default:
+		if req.ID == nil {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "метод не поддерживается: " + req.Method}
+	}
+}
+
+func (s *Server) call(t tool, args json.RawMessage) callResult {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	project, err := s.opts.Load()
+	if err != nil {
+		return errorResult(err)
+	}
+
+	value, err := t.handler(project, args)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	if text, ok := value.(string); ok {
+		return callResult{Content: []content{{Type: "text", Text: text}}}
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return errorResult(err)
+	}
+	return callResult{Content: []content{{Type: "text", Text: string(data)}}}
+}
+
+func (s *Server) reply(id json.RawMessage, result any, rpcErr *rpcError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	data, err := json.Marshal(response{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: codeInvalidRequest, Message: err.Error()}})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.out, "%s\n", data)
+}
+
+func errorResult(err error) callResult {
+	return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+}
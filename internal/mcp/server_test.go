@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+func writeSpec(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	root := t.TempDir()
+
+	writeSpec(t, filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Service](service.md)\n")
+	writeSpec(t, filepath.Join(root, "specs", "service.md"), "# Service\n\n## Responsibility\nДелает работу.\n")
+	writeSpec(t, filepath.Join(root, "notes.md"), "# Notes\n")
+
+	load := func() (*spec.Project, error) {
+		return spec.LoadProject([]string{filepath.Join(root, "specs")}, spec.DiscoverOptions{Root: root}, nil)
+	}
+	return NewServer(Options{Load: load, Root: root, Version: "test"}), root
+}
+
+func roundTrip(t *testing.T, s *Server, requests ...string) []response {
+	t.Helper()
+
+	var out strings.Builder
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	var responses []response
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp struct {
+			response
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("некорректный ответ %q: %v", scanner.Text(), err)
+		}
+		resp.response.Result = resp.Result
+		responses = append(responses, resp.response)
+	}
+	return responses
+}
+
+func callTool(t *testing.T, s *Server, name string, args any) callResult {
+	t.Helper()
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := roundTrip(t, s, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, name, data))
+	if len(responses) != 1 {
+		t.Fatalf("ожидался один ответ, получено %d", len(responses))
+	}
+	if responses[0].Error != nil {
+		t.Fatalf("ошибка JSON-RPC: %s", responses[0].Error.Message)
+	}
+
+	var result callResult
+	if err := json.Unmarshal(responses[0].Result.(json.RawMessage), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestInitializeAndToolsList(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"unknown"}`,
+	)
+	if len(responses) != 3 {
+		t.Fatalf("ожидалось 3 ответа, получено %d", len(responses))
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(responses[0].Result.(json.RawMessage), &init); err != nil {
+		t.Fatal(err)
+	}
+	if init.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocolVersion = %q, ожидалось 2024-11-05", init.ProtocolVersion)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[1].Result.(json.RawMessage), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tools) != len(s.tools) {
+		t.Errorf("tools/list вернул %d инструментов, ожидалось %d", len(list.Tools), len(s.tools))
+	}
+
+	if responses[2].Error == nil || responses[2].Error.Code != codeMethodNotFound {
+		t.Errorf("ожидалась ошибка %d для неизвестного метода, получено %+v", codeMethodNotFound, responses[2].Error)
+	}
+}
+
+func TestGetSpec(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result := callTool(t, s, "get_spec", map[string]string{"path": "specs/api.md"})
+	if result.IsError {
+		t.Fatalf("get_spec вернул ошибку: %s", result.Content[0].Text)
+	}
+
+	var got specJSON
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "specs/api.md" || got.Title != "API" || !got.Root {
+		t.Errorf("неожиданная спецификация: %+v", got)
+	}
+	if len(got.Dependencies) != 1 || got.Dependencies[0] != "specs/service.md" {
+		t.Errorf("Dependencies = %v, ожидалось [specs/service.md]", got.Dependencies)
+	}
+}
+
+func TestToolPathsOutsideProjectRejected(t *testing.T) {
+	s, root := newTestServer(t)
+
+	outside := filepath.Join(filepath.Dir(root), "outside.md")
+	writeSpec(t, outside, "# Outside\n")
+
+	paths := []string{
+		"/etc/passwd",
+		"/etc/hostname",
+		"../../etc/passwd",
+		"../outside.md",
+		outside,
+		"specs/../../outside.md",
+		"notes.md",
+		"specs/missing.md",
+	}
+
+	for _, tool := range []string{"get_spec", "spec_tree", "impact", "lint"} {
+		for _, path := range paths {
+			result := callTool(t, s, tool, map[string]string{"path": path})
+			if !result.IsError {
+				t.Errorf("%s(%q): ожидалась ошибка, получено %s", tool, path, result.Content[0].Text)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		result := callTool(t, s, "create_change_plan", map[string]any{"name": "escape", "specs": []string{path}})
+		if !result.IsError {
+			t.Errorf("create_change_plan(%q): ожидалась ошибка, получено %s", path, result.Content[0].Text)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, changesDir)); err == nil {
+		t.Errorf("план изменений создан для пути вне графа")
+	}
+}
+
+func TestLintMatchesCLISchema(t *testing.T) {
+	s, root := newTestServer(t)
+	writeSpec(t, filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Gone](gone.md)\n")
+
+	result := callTool(t, s, "lint", map[string]string{"path": "specs/api.md"})
+	if result.IsError {
+		t.Fatalf("lint вернул ошибку: %s", result.Content[0].Text)
+	}
+
+	var got spec.DiagnosticsJSON
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	var cli strings.Builder
+	diagnostics := spec.LintSpec(spec.ParseContent(filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Gone](gone.md)\n"), spec.LintOptions{})
+	if err := spec.WriteDiagnostics(&cli, diagnostics, spec.ReportOptions{Format: spec.FormatJSON, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	var want spec.DiagnosticsJSON
+	if err := json.Unmarshal([]byte(cli.String()), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Errors != 1 || len(got.Diagnostics) != len(want.Diagnostics) {
+		t.Fatalf("lint = %+v, ожидалось %+v", got, want)
+	}
+	for i := range got.Diagnostics {
+		if got.Diagnostics[i] != want.Diagnostics[i] {
+			t.Errorf("диагностика %d = %+v, в lint --format json %+v", i, got.Diagnostics[i], want.Diagnostics[i])
+		}
+	}
+}
+
+func TestCreateChangePlan(t *testing.T) {
+	s, root := newTestServer(t)
+
+	result := callTool(t, s, "create_change_plan", map[string]any{
+		"name":  "Add field",
+		"specs": []string{"specs/service.md"},
+	})
+	if result.IsError {
+		t.Fatalf("create_change_plan вернул ошибку: %s", result.Content[0].Text)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(root, changesDir, "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("ожидался один план изменений, найдено %d", len(matches))
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "api.md") {
+		t.Errorf("план не упоминает косвенно затронутую specs/api.md:\n%s", data)
+	}
+}
@@ -0,0 +1,535 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+const changesDir = "spec_changes"
+
+type tool struct {
+	name        string
+	description string
+	schema      map[string]any
+	handler     func(project *spec.Project, args json.RawMessage) (any, error)
+}
+
+type specJSON struct {
+	Path         string        `json:"path"`
+	Title        string        `json:"title"`
+	Root         bool          `json:"root"`
+	Sections     []sectionJSON `json:"sections"`
+	Dependencies []string      `json:"dependencies"`
+	Dependents   []string      `json:"dependents"`
+	Content      string        `json:"content"`
+}
+
+type sectionJSON struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+type treeNode struct {
+	Path     string      `json:"path"`
+	Title    string      `json:"title,omitempty"`
+	Missing  bool        `json:"missing,omitempty"`
+	Cycle    bool        `json:"cycle,omitempty"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+type searchHit struct {
+	Path    string   `json:"path"`
+	Title   string   `json:"title"`
+	Score   int      `json:"score"`
+	Matches []string `json:"matches"`
+}
+
+func (s *Server) registerTools() []tool {
+	pathArg := map[string]any{"type": "string", "description": "путь к спецификации относительно корня проекта"}
+
+	return []tool{
+		{
+			name:        "get_spec",
+			description: "Прочитать спецификацию: текст, секции, прямые зависимости и зависящие спеки.",
+			schema:      objectSchema(map[string]any{"path": pathArg}, "path"),
+			handler:     s.getSpec,
+		},
+		{
+			name:        "spec_tree",
+			description: "Дерево зависимостей от спецификации (или от всех корневых спек, если path не указан).",
+			schema: objectSchema(map[string]any{
+				"path":  pathArg,
+				"depth": map[string]any{"type": "integer", "description": "максимальная глубина, 0 — без ограничения"},
+			}),
+			handler: s.specTree,
+		},
+		{
+			name:        "impact",
+			description: "Спецификации, которые прямо или транзитивно зависят от указанной и затрагиваются её изменением.",
+			schema:      objectSchema(map[string]any{"path": pathArg}, "path"),
+			handler:     s.impact,
+		},
+		{
+			name:        "lint",
+			description: "Проверить спецификации проекта или одну спецификацию правилами spec-agent lint.",
+			schema:      objectSchema(map[string]any{"path": pathArg}),
+			handler:     s.lint,
+		},
+		{
+			name:        "create_change_plan",
+			description: "Создать план изменений spec_changes/YYYYMMDD_HHMM_<name>.md с чек-листами по спецификациям и коду.",
+			schema: objectSchema(map[string]any{
+				"name":         map[string]any{"type": "string", "description": "короткое описание для имени файла, например add_user_roles"},
+				"title":        map[string]any{"type": "string", "description": "заголовок плана"},
+				"description":  map[string]any{"type": "string", "description": "суть бизнес-изменения"},
+				"specs":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "затронутые спецификации"},
+				"spec_changes": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "пункты изменений в спецификациях"},
+				"code_changes": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "пункты изменений в коде"},
+			}, "name", "specs"),
+			handler: s.createChangePlan,
+		},
+		{
+			name:        "search_specs",
+			description: "Полнотекстовый поиск по спецификациям проекта (все слова запроса должны встречаться в спеке).",
+			schema: objectSchema(map[string]any{
+				"query": map[string]any{"type": "string"},
+				"limit": map[string]any{"type": "integer", "description": "максимум результатов, по умолчанию 20"},
+			}, "query"),
+			handler: s.searchSpecs,
+		},
+	}
+}
+
+func (s *Server) getSpec(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	path, err := s.specPath(project, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := project.Cache.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("спецификация не найдена: %s", args.Path)
+	}
+
+	result := specJSON{
+		Path:         s.rel(path),
+		Title:        sp.Title,
+		Root:         slices.Contains(project.RootSpecs, path),
+		Sections:     []sectionJSON{},
+		Dependencies: s.relPaths(spec.Dependencies(project.Graph, path)),
+		Dependents:   s.relPaths(spec.Dependents(project.Graph, path)),
+		Content:      sp.Content,
+	}
+	for _, section := range sp.Sections {
+		result.Sections = append(result.Sections, sectionJSON{
+			Name:      section.Name,
+			Kind:      string(section.Kind),
+			StartLine: section.Range.Start.Line,
+			EndLine:   section.Range.End.Line,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Server) specTree(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Path  string `json:"path"`
+		Depth int    `json:"depth"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	starts := project.RootSpecs
+	if args.Path != "" {
+		path, err := s.specPath(project, args.Path)
+		if err != nil {
+			return nil, err
+		}
+		starts = []string{path}
+	}
+
+	var build func(path string, depth int, stack map[string]bool) *treeNode
+	build = func(path string, depth int, stack map[string]bool) *treeNode {
+		node := &treeNode{Path: s.rel(path)}
+		if sp, err := project.Cache.ParseFile(path); err == nil {
+			node.Title = sp.Title
+		} else {
+			node.Missing = true
+		}
+
+		if stack[path] {
+			node.Cycle = true
+			return node
+		}
+		if args.Depth > 0 && depth >= args.Depth {
+			return node
+		}
+
+		stack[path] = true
+		for _, dep := range spec.Dependencies(project.Graph, path) {
+			node.Children = append(node.Children, build(dep, depth+1, stack))
+		}
+		delete(stack, path)
+
+		return node
+	}
+
+	trees := make([]*treeNode, 0, len(starts))
+	for _, start := range starts {
+		trees = append(trees, build(start, 0, map[string]bool{}))
+	}
+
+	return map[string]any{"roots": trees}, nil
+}
+
+func (s *Server) impact(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	path, err := s.specPath(project, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		Path  string `json:"path"`
+		Depth int    `json:"depth"`
+	}
+	affected := []entry{}
+	for _, e := range spec.Impact(project.Graph, path) {
+		affected = append(affected, entry{Path: s.rel(e.Path), Depth: e.Depth})
+	}
+
+	return map[string]any{"path": s.rel(path), "affected": affected}, nil
+}
+
+func (s *Server) lint(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	var diagnostics []spec.Diagnostic
+	if args.Path != "" {
+		path, err := s.specPath(project, args.Path)
+		if err != nil {
+			return nil, err
+		}
+		sp, err := project.Cache.ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("спецификация не найдена: %s", args.Path)
+		}
+		diagnostics = spec.LintSpec(sp, project.Lint)
+	} else {
+		diagnostics = spec.Lint(project.Graph, project.Cache, project.Lint)
+	}
+
+	return spec.NewDiagnosticsJSON(diagnostics, s.opts.Root), nil
+}
+
+func (s *Server) createChangePlan(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Name        string   `json:"name"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Specs       []string `json:"specs"`
+		SpecChanges []string `json:"spec_changes"`
+		CodeChanges []string `json:"code_changes"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	slug := slugify(args.Name)
+	if slug == "" {
+		return nil, fmt.Errorf("не указано имя плана (name)")
+	}
+	if len(args.Specs) == 0 {
+		return nil, fmt.Errorf("не указаны затронутые спецификации (specs)")
+	}
+
+	var specs []string
+	for _, p := range args.Specs {
+		path, err := s.specPath(project, p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("спецификация не найдена: %s", p)
+		}
+		specs = append(specs, path)
+	}
+
+	indirect := map[string]bool{}
+	for _, path := range specs {
+		for _, e := range spec.Impact(project.Graph, path) {
+			if !slices.Contains(specs, e.Path) {
+				indirect[e.Path] = true
+			}
+		}
+	}
+
+	now := time.Now()
+	title := args.Title
+	if title == "" {
+		title = strings.ReplaceAll(slug, "_", " ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "Создан: %s\n\n", now.Format("2006-01-02 15:04"))
+	if args.Description != "" {
+		fmt.Fprintf(&b, "## Описание\n\n%s\n\n", strings.TrimSpace(args.Description))
+	}
+
+	b.WriteString("## Затронутые спецификации\n\n")
+	for _, path := range specs {
+		fmt.Fprintf(&b, "- [%s](%s)\n", s.rel(path), s.planLink(path))
+	}
+	if len(indirect) > 0 {
+		b.WriteString("\nЗависят от изменяемых спек (проверить):\n\n")
+		for _, path := range sortedKeys(indirect) {
+			fmt.Fprintf(&b, "- [%s](%s)\n", s.rel(path), s.planLink(path))
+		}
+	}
+
+	b.WriteString("\n## Изменения в спецификациях\n\n")
+	if len(args.SpecChanges) == 0 {
+		for _, path := range specs {
+			fmt.Fprintf(&b, "- [ ] Обновить %s\n", s.rel(path))
+		}
+	}
+	for _, item := range args.SpecChanges {
+		fmt.Fprintf(&b, "- [ ] %s\n", item)
+	}
+
+	b.WriteString("\n## Изменения в коде\n\n")
+	if len(args.CodeChanges) == 0 {
+		for _, path := range specs {
+			fmt.Fprintf(&b, "- [ ] Привести в соответствие %s\n", s.rel(strings.TrimSuffix(path, ".md")+".go"))
+		}
+	}
+	for _, item := range args.CodeChanges {
+		fmt.Fprintf(&b, "- [ ] %s\n", item)
+	}
+
+	b.WriteString("\n## Порядок выполнения\n\n")
+	b.WriteString("1. Обновить спецификации\n")
+	b.WriteString("2. Обновить код\n")
+	b.WriteString("3. Запустить spec-agent lint\n")
+	b.WriteString("4. Отметить выполненные пункты плана\n")
+
+	dir := filepath.Join(s.opts.Root, changesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, now.Format("20060102_1504")+"_"+slug+".md")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("план уже существует: %s", s.rel(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(b.String()); err != nil {
+		return nil, fmt.Errorf("не удалось записать план: %w", err)
+	}
+
+	return map[string]any{"path": s.rel(path), "content": b.String()}, nil
+}
+
+func (s *Server) searchSpecs(project *spec.Project, raw json.RawMessage) (any, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(args.Query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("пустой запрос")
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+
+	hits := []searchHit{}
+	for path, sp := range project.Specs() {
+		content := strings.ToLower(sp.Content)
+		title := strings.ToLower(sp.Title)
+
+		hit := searchHit{Path: s.rel(path), Title: sp.Title}
+		matched := true
+		for _, term := range terms {
+			count := strings.Count(content, term)
+			if count == 0 {
+				matched = false
+				break
+			}
+			hit.Score += count
+			if strings.Contains(title, term) {
+				hit.Score += 5
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		for _, line := range strings.Split(sp.Content, "\n") {
+			lower := strings.ToLower(line)
+			for _, term := range terms {
+				if strings.Contains(lower, term) {
+					hit.Matches = append(hit.Matches, strings.TrimSpace(line))
+					break
+				}
+			}
+			if len(hit.Matches) == 3 {
+				break
+			}
+		}
+
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	if len(hits) > args.Limit {
+		hits = hits[:args.Limit]
+	}
+
+	return map[string]any{"results": hits}, nil
+}
+
+func (s *Server) resolve(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("не указан путь к спецификации (path)")
+	}
+	root, err := filepath.Abs(s.opts.Root)
+	if err != nil {
+		return "", err
+	}
+	abs := filepath.FromSlash(path)
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(root, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("путь вне корня проекта: %s", path)
+	}
+	return abs, nil
+}
+
+func (s *Server) specPath(project *spec.Project, path string) (string, error) {
+	abs, err := s.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := project.Graph.Nodes[abs]; !ok {
+		return "", fmt.Errorf("спецификация не входит в граф: %s", path)
+	}
+	return abs, nil
+}
+
+func (s *Server) rel(path string) string {
+	rel, err := filepath.Rel(s.opts.Root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (s *Server) relPaths(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, s.rel(path))
+	}
+	return result
+}
+
+func (s *Server) planLink(path string) string {
+	rel, err := filepath.Rel(filepath.Join(s.opts.Root, changesDir), path)
+	if err != nil {
+		return s.rel(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func decodeArgs(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("некорректные аргументы: %w", err)
+	}
+	return nil
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func slugify(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
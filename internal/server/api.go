@@ -0,0 +1,324 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+type ProjectLoader = spec.ProjectLoader
+
+type SpecSummary struct {
+	Path         string `json:"path"`
+	Title        string `json:"title"`
+	Exists       bool   `json:"exists"`
+	Root         bool   `json:"root"`
+	Dependencies int    `json:"dependencies"`
+	Dependents   int    `json:"dependents"`
+}
+
+type SpecDetail struct {
+	Path         string        `json:"path"`
+	Title        string        `json:"title"`
+	Sections     []SectionJSON `json:"sections"`
+	Links        []LinkJSON    `json:"links"`
+	Refs         []RefJSON     `json:"refs"`
+	Dependencies []string      `json:"dependencies"`
+	Dependents   []string      `json:"dependents"`
+	Root         bool          `json:"root"`
+}
+
+type SectionJSON struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	Content   string `json:"content"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+type LinkJSON struct {
+	Title string `json:"title"`
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+}
+
+type RefJSON struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Anchor string `json:"anchor,omitempty"`
+	Line   int    `json:"line"`
+}
+
+type GraphJSON struct {
+	Nodes []NodeJSON `json:"nodes"`
+	Edges []EdgeJSON `json:"edges"`
+}
+
+type NodeJSON struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Exists bool   `json:"exists"`
+}
+
+type EdgeJSON struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  string   `json:"kind"`
+	Kinds []string `json:"kinds"`
+}
+
+type ImpactJSON struct {
+	Path     string            `json:"path"`
+	Affected []ImpactEntryJSON `json:"affected"`
+}
+
+type ImpactEntryJSON struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+type LintJSON = spec.DiagnosticsJSON
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+type API struct {
+	load ProjectLoader
+	root string
+	mux  *http.ServeMux
+}
+
+func NewAPI(load ProjectLoader, root string) *API {
+	a := &API{load: load, root: root, mux: http.NewServeMux()}
+
+	a.mux.HandleFunc("GET /api/specs", a.handleSpecs)
+	a.mux.HandleFunc("GET /api/specs/{path...}", a.handleSpec)
+	a.mux.HandleFunc("GET /api/graph", a.handleGraph)
+	a.mux.HandleFunc("GET /api/impact/{path...}", a.handleImpact)
+	a.mux.HandleFunc("GET /api/lint", a.handleLint)
+
+	return a
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+func (a *API) handleSpecs(w http.ResponseWriter, r *http.Request) {
+	project, ok := a.project(w)
+	if !ok {
+		return
+	}
+
+	roots := rootSet(project)
+	result := []SpecSummary{}
+
+	for _, path := range project.Paths() {
+		summary := SpecSummary{
+			Path:         relPath(a.root, path),
+			Title:        filepath.Base(path),
+			Root:         roots[path],
+			Dependencies: len(spec.Dependencies(project.Graph, path)),
+			Dependents:   len(spec.Dependents(project.Graph, path)),
+		}
+		if s, err := project.Cache.ParseFile(path); err == nil {
+			summary.Exists = true
+			if s.Title != "" {
+				summary.Title = s.Title
+			}
+		}
+		result = append(result, summary)
+	}
+
+	writeJSON(w, http.StatusOK, map[string][]SpecSummary{"specs": result})
+}
+
+func (a *API) handleSpec(w http.ResponseWriter, r *http.Request) {
+	project, ok := a.project(w)
+	if !ok {
+		return
+	}
+
+	path, ok := resolveSpec(w, project, a.root, r.PathValue("path"))
+	if !ok {
+		return
+	}
+
+	s, err := project.Cache.ParseFile(path)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "спецификация не найдена: "+r.PathValue("path"))
+		return
+	}
+
+	detail := SpecDetail{
+		Path:         relPath(a.root, path),
+		Title:        s.Title,
+		Sections:     sectionsJSON(s),
+		Links:        []LinkJSON{},
+		Refs:         []RefJSON{},
+		Dependencies: relPaths(a.root, spec.Dependencies(project.Graph, path)),
+		Dependents:   relPaths(a.root, spec.Dependents(project.Graph, path)),
+		Root:         rootSet(project)[path],
+	}
+	for _, link := range s.Links {
+		detail.Links = append(detail.Links, LinkJSON{Title: link.Title, Path: link.Path, Line: link.Range.Start.Line})
+	}
+	for _, ref := range s.Refs {
+		detail.Refs = append(detail.Refs, RefJSON{Kind: ref.Kind, Path: ref.Path, Anchor: ref.Anchor, Line: ref.Range.Start.Line})
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (a *API) handleGraph(w http.ResponseWriter, r *http.Request) {
+	project, ok := a.project(w)
+	if !ok {
+		return
+	}
+
+	result := GraphJSON{Nodes: []NodeJSON{}, Edges: []EdgeJSON{}}
+
+	for _, path := range project.Paths() {
+		node := project.Graph.Nodes[path]
+		n := NodeJSON{ID: relPath(a.root, path), Title: filepath.Base(path), Type: node.Type}
+		if s, err := project.Cache.ParseFile(path); err == nil {
+			n.Exists = true
+			if s.Title != "" {
+				n.Title = s.Title
+			}
+		}
+		result.Nodes = append(result.Nodes, n)
+	}
+
+	for _, edge := range project.Graph.Edges {
+		result.Edges = append(result.Edges, EdgeJSON{
+			From:  relPath(a.root, edge.From),
+			To:    relPath(a.root, edge.To),
+			Kind:  edge.Kind,
+			Kinds: edge.Kinds,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *API) handleImpact(w http.ResponseWriter, r *http.Request) {
+	project, ok := a.project(w)
+	if !ok {
+		return
+	}
+
+	path, ok := resolveSpec(w, project, a.root, r.PathValue("path"))
+	if !ok {
+		return
+	}
+
+	result := ImpactJSON{Path: relPath(a.root, path), Affected: []ImpactEntryJSON{}}
+	for _, entry := range spec.Impact(project.Graph, path) {
+		result.Affected = append(result.Affected, ImpactEntryJSON{Path: relPath(a.root, entry.Path), Depth: entry.Depth})
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *API) handleLint(w http.ResponseWriter, r *http.Request) {
+	project, ok := a.project(w)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lintJSON(a.root, spec.Lint(project.Graph, project.Cache, project.Lint)))
+}
+
+func (a *API) project(w http.ResponseWriter) (*spec.Project, bool) {
+	project, err := a.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return project, true
+}
+
+func lintJSON(root string, diagnostics []spec.Diagnostic) LintJSON {
+	return spec.NewDiagnosticsJSON(diagnostics, root)
+}
+
+func resolveSpec(w http.ResponseWriter, project *spec.Project, root, rel string) (string, bool) {
+	base, err := filepath.Abs(root)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return "", false
+	}
+
+	local := filepath.FromSlash(rel)
+	if filepath.IsAbs(local) || !filepath.IsLocal(local) {
+		writeError(w, http.StatusBadRequest, "путь вне корня проекта: "+rel)
+		return "", false
+	}
+
+	abs := filepath.Join(base, local)
+	if _, ok := project.Graph.Nodes[abs]; !ok {
+		writeError(w, http.StatusNotFound, "спецификация не найдена: "+rel)
+		return "", false
+	}
+
+	return abs, true
+}
+
+func rootSet(project *spec.Project) map[string]bool {
+	roots := map[string]bool{}
+	for _, root := range project.RootSpecs {
+		roots[root] = true
+	}
+	return roots
+}
+
+func relPath(root, path string) string {
+	if root == "" {
+		return spec.RelPath(path)
+	}
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func relPaths(root string, paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, relPath(root, path))
+	}
+	return result
+}
+
+func sectionsJSON(s *spec.Spec) []SectionJSON {
+	sections := make([]SectionJSON, 0, len(s.Sections))
+	for _, section := range s.Sections {
+		sections = append(sections, SectionJSON{
+			Name:      section.Name,
+			Kind:      string(section.Kind),
+			Content:   strings.TrimSpace(section.Body),
+			StartLine: section.Range.Start.Line,
+			EndLine:   section.Range.End.Line,
+		})
+	}
+	return sections
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorJSON{Error: message})
+}
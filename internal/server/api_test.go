@@ -0,0 +1,215 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+func writeSpec(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestLoader(t *testing.T) (ProjectLoader, string) {
+	t.Helper()
+	root := t.TempDir()
+
+	writeSpec(t, filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Service](service.md)\n- [Gone](gone.md)\n\n## Flow\n1. Вызывает сервис\n   → calls: service.md\n")
+	writeSpec(t, filepath.Join(root, "specs", "service.md"), "# Service\n\n## Responsibility\nДелает работу.\n")
+	writeSpec(t, filepath.Join(root, "notes.md"), "# Notes\n")
+
+	load := func() (*spec.Project, error) {
+		return spec.LoadProject([]string{filepath.Join(root, "specs")}, spec.DiscoverOptions{Root: root}, nil)
+	}
+	return load, root
+}
+
+func newTestAPI(t *testing.T) *API {
+	t.Helper()
+	load, root := newTestLoader(t)
+	return NewAPI(load, root)
+}
+
+func getJSON(t *testing.T, h http.Handler, target string, want int, v any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if rec.Code != want {
+		t.Fatalf("GET %s: статус %d, ожидался %d: %s", target, rec.Code, want, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("GET %s: Content-Type = %q", target, ct)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("GET %s: некорректный JSON: %v", target, err)
+		}
+	}
+}
+
+func TestAPISpecs(t *testing.T) {
+	api := newTestAPI(t)
+
+	var got struct {
+		Specs []SpecSummary `json:"specs"`
+	}
+	getJSON(t, api, "/api/specs", http.StatusOK, &got)
+
+	byPath := map[string]SpecSummary{}
+	for _, s := range got.Specs {
+		byPath[s.Path] = s
+	}
+	if len(byPath) != 3 {
+		t.Fatalf("ожидалось 3 спеки, получено %+v", got.Specs)
+	}
+
+	if s := byPath["specs/api.md"]; s.Title != "API" || !s.Root || !s.Exists || s.Dependencies != 2 {
+		t.Errorf("неожиданная сводка specs/api.md: %+v", s)
+	}
+	if s := byPath["specs/service.md"]; s.Title != "Service" || s.Root || s.Dependents != 1 {
+		t.Errorf("неожиданная сводка specs/service.md: %+v", s)
+	}
+	if s, ok := byPath["specs/gone.md"]; !ok || s.Exists {
+		t.Errorf("specs/gone.md должна быть в списке как несуществующая: %+v", s)
+	}
+}
+
+func TestAPISpec(t *testing.T) {
+	api := newTestAPI(t)
+
+	var got SpecDetail
+	getJSON(t, api, "/api/specs/specs/api.md", http.StatusOK, &got)
+
+	if got.Path != "specs/api.md" || got.Title != "API" || !got.Root {
+		t.Errorf("неожиданная спека: %+v", got)
+	}
+	if !slices.Equal(got.Dependencies, []string{"specs/gone.md", "specs/service.md"}) {
+		t.Errorf("Dependencies = %v", got.Dependencies)
+	}
+	if len(got.Links) != 2 || got.Links[0].Path != "service.md" {
+		t.Errorf("Links = %+v", got.Links)
+	}
+	if len(got.Sections) != 2 || got.Sections[0].Kind != string(spec.SectionDependencies) {
+		t.Errorf("Sections = %+v", got.Sections)
+	}
+}
+
+func TestAPISpecNotFound(t *testing.T) {
+	api := newTestAPI(t)
+
+	for _, target := range []string{
+		"/api/specs/specs/missing.md",
+		"/api/specs/notes.md",
+		"/api/specs/specs/gone.md",
+		"/api/impact/specs/missing.md",
+	} {
+		var got errorJSON
+		getJSON(t, api, target, http.StatusNotFound, &got)
+		if got.Error == "" {
+			t.Errorf("GET %s: пустое сообщение об ошибке", target)
+		}
+	}
+}
+
+func TestAPIPathEscape(t *testing.T) {
+	api := newTestAPI(t)
+
+	for _, target := range []string{
+		"/api/specs/..%2F..%2Fetc%2Fpasswd",
+		"/api/specs/specs%2F..%2F..%2Foutside.md",
+		"/api/specs/%2Fetc%2Fpasswd",
+		"/api/impact/..%2F..%2Fetc%2Fpasswd",
+		"/api/impact/%2Fetc%2Fpasswd",
+	} {
+		var got errorJSON
+		getJSON(t, api, target, http.StatusBadRequest, &got)
+		if got.Error == "" {
+			t.Errorf("GET %s: пустое сообщение об ошибке", target)
+		}
+	}
+}
+
+func TestAPIGraph(t *testing.T) {
+	api := newTestAPI(t)
+
+	var got GraphJSON
+	getJSON(t, api, "/api/graph", http.StatusOK, &got)
+
+	if len(got.Nodes) != 3 {
+		t.Errorf("ожидалось 3 узла, получено %+v", got.Nodes)
+	}
+	for _, n := range got.Nodes {
+		if filepath.IsAbs(n.ID) {
+			t.Errorf("ID узла должен быть относительным: %s", n.ID)
+		}
+	}
+
+	var edges []EdgeJSON
+	for _, e := range got.Edges {
+		if e.From == "specs/api.md" && e.To == "specs/service.md" {
+			edges = append(edges, e)
+		}
+	}
+	if len(edges) != 1 {
+		t.Fatalf("ожидалось одно ребро specs/api.md → specs/service.md, получено %+v", edges)
+	}
+	if edges[0].Kind != "depends" || !slices.Equal(edges[0].Kinds, []string{"depends", "calls"}) {
+		t.Errorf("неожиданные виды ребра: %+v", edges[0])
+	}
+}
+
+func TestAPIImpact(t *testing.T) {
+	api := newTestAPI(t)
+
+	var got ImpactJSON
+	getJSON(t, api, "/api/impact/specs/service.md", http.StatusOK, &got)
+
+	if got.Path != "specs/service.md" {
+		t.Errorf("Path = %q", got.Path)
+	}
+	if len(got.Affected) != 1 || got.Affected[0] != (ImpactEntryJSON{Path: "specs/api.md", Depth: 1}) {
+		t.Errorf("Affected = %+v", got.Affected)
+	}
+}
+
+func TestAPILint(t *testing.T) {
+	api := newTestAPI(t)
+
+	var got LintJSON
+	getJSON(t, api, "/api/lint", http.StatusOK, &got)
+
+	if got.Errors == 0 {
+		t.Fatalf("ожидалась ошибка broken-link для specs/gone.md: %+v", got)
+	}
+	idx := slices.IndexFunc(got.Diagnostics, func(d spec.DiagnosticJSON) bool { return d.Rule == "broken-link" })
+	if idx < 0 {
+		t.Fatalf("нет диагностики broken-link: %+v", got.Diagnostics)
+	}
+	if d := got.Diagnostics[idx]; d.File != "specs/api.md" || d.Range.Start.Line != 5 || d.Range.End.Column <= d.Range.Start.Column || d.Fix == "" {
+		t.Errorf("неожиданная диагностика: %+v", d)
+	}
+}
+
+func TestAPILoaderError(t *testing.T) {
+	api := NewAPI(func() (*spec.Project, error) { return nil, os.ErrNotExist }, t.TempDir())
+
+	var got errorJSON
+	getJSON(t, api, "/api/specs", http.StatusInternalServerError, &got)
+	if got.Error == "" {
+		t.Error("пустое сообщение об ошибке")
+	}
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	agentfs "github.com/SmirnovND/spec-agent/internal/fs"
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+var protectedRules = map[string]bool{
+	"broken-link":     true,
+	"missing-section": true,
+}
+
+type sourceJSON struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type previewJSON struct {
+	HTML string   `json:"html"`
+	Lint LintJSON `json:"lint"`
+}
+
+type saveJSON struct {
+	Path  string   `json:"path"`
+	Lint  LintJSON `json:"lint"`
+	Error string   `json:"error,omitempty"`
+}
+
+type editorPage struct {
+	Path    string
+	PageURL string
+}
+
+func (s *Server) registerEditor() {
+	s.mux.Handle("GET /edit/{path...}", s.requireAuth(http.HandlerFunc(s.handleEditor)))
+	s.mux.Handle("GET /api/source/{path...}", s.requireAuth(http.HandlerFunc(s.handleSource)))
+	s.mux.Handle("PUT /api/source/{path...}", s.requireAuth(http.HandlerFunc(s.handleSave)))
+	s.mux.Handle("POST /api/preview/{path...}", s.requireAuth(http.HandlerFunc(s.handlePreview)))
+}
+
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	if s.opts.BasicAuth == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		given := []byte(user + ":" + pass)
+		if !ok || subtle.ConstantTimeCompare(given, []byte(s.opts.BasicAuth)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="spec-agent editor", charset="UTF-8"`)
+			writeError(w, http.StatusUnauthorized, "требуется авторизация")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleEditor(w http.ResponseWriter, r *http.Request) {
+	_, specPath, ok := s.editableSpec(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := template.New("editor.html").ParseFS(agentfs.Templates(s.opts.TemplatesDir), "editor.html", "partials.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	page := editorPage{Path: relPath(s.opts.Root, specPath), PageURL: spec.PageFilename(s.opts.Root, specPath)}
+	if err := tmpl.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (s *Server) handleSource(w http.ResponseWriter, r *http.Request) {
+	_, specPath, ok := s.editableSpec(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "не удалось прочитать спецификацию: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sourceJSON{Path: relPath(s.opts.Root, specPath), Content: string(data)})
+}
+
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	project, specPath, ok := s.editableSpec(w, r)
+	if !ok {
+		return
+	}
+
+	var body sourceJSON
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректное тело запроса: "+err.Error())
+		return
+	}
+
+	parsed := spec.ParseContentWith(specPath, body.Content, project.Cache.SectionNames())
+	writeJSON(w, http.StatusOK, previewJSON{
+		HTML: spec.RenderMarkdown(body.Content),
+		Lint: lintJSON(s.opts.Root, spec.LintSpec(parsed, project.Lint)),
+	})
+}
+
+func (s *Server) handleSave(w http.ResponseWriter, r *http.Request) {
+	project, specPath, ok := s.editableSpec(w, r)
+	if !ok {
+		return
+	}
+
+	var body sourceJSON
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректное тело запроса: "+err.Error())
+		return
+	}
+
+	previous, err := os.ReadFile(specPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "не удалось прочитать спецификацию: "+err.Error())
+		return
+	}
+
+	names := project.Cache.SectionNames()
+	before := spec.LintSpec(spec.ParseContentWith(specPath, string(previous), names), project.Lint)
+	after := spec.LintSpec(spec.ParseContentWith(specPath, body.Content, names), project.Lint)
+
+	if introduced := introducedProblems(before, after); len(introduced) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, saveJSON{
+			Path:  relPath(s.opts.Root, specPath),
+			Lint:  lintJSON(s.opts.Root, introduced),
+			Error: "изменения добавляют битые ссылки или удаляют обязательные секции",
+		})
+		return
+	}
+
+	if err := writeFileAtomic(specPath, []byte(body.Content)); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s.opts.OnSave != nil {
+		s.opts.OnSave(specPath)
+	}
+
+	writeJSON(w, http.StatusOK, saveJSON{Path: relPath(s.opts.Root, specPath), Lint: lintJSON(s.opts.Root, after)})
+}
+
+func (s *Server) editableSpec(w http.ResponseWriter, r *http.Request) (*spec.Project, string, bool) {
+	project, err := s.opts.Loader()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, "", false
+	}
+
+	specPath, ok := resolveSpec(w, project, s.opts.Root, r.PathValue("path"))
+	return project, specPath, ok
+}
+
+func (s *Server) editLink(r *http.Request) string {
+	rel, ok := s.editLinks()[path.Base(r.URL.Path)]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf(`<a href="/edit/%s" style="position: fixed; right: 24px; bottom: 24px; padding: 10px 18px; border-radius: 24px; background: var(--accent); color: var(--header-text); text-decoration: none; box-shadow: 0 4px 12px rgba(0, 0, 0, 0.2);">✏️ Редактировать</a>
+`, template.HTMLEscapeString(rel))
+}
+
+func (s *Server) editLinks() map[string]string {
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	if s.links != nil {
+		return s.links
+	}
+
+	project, err := s.opts.Loader()
+	if err != nil {
+		return nil
+	}
+
+	links := map[string]string{}
+	for _, p := range project.Paths() {
+		if fileExists(p) {
+			links[spec.PageFilename(s.opts.Root, p)] = relPath(s.opts.Root, p)
+		}
+	}
+	s.links = links
+	return links
+}
+
+func introducedProblems(before, after []spec.Diagnostic) []spec.Diagnostic {
+	existing := map[string]bool{}
+	for _, d := range before {
+		existing[d.RuleID+"\x00"+d.Message] = true
+	}
+
+	var introduced []spec.Diagnostic
+	for _, d := range after {
+		if protectedRules[d.RuleID] && !existing[d.RuleID+"\x00"+d.Message] {
+			introduced = append(introduced, d)
+		}
+	}
+
+	return introduced
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	cleanup := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		return cleanup(fmt.Errorf("не удалось записать спецификацию: %w", err))
+	}
+	if err := tmp.Sync(); err != nil {
+		return cleanup(fmt.Errorf("не удалось записать спецификацию: %w", err))
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return cleanup(fmt.Errorf("не удалось записать спецификацию: %w", err))
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("не удалось записать спецификацию: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("не удалось сохранить спецификацию: %w", err)
+	}
+
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type reloadHub struct {
+	mu      sync.Mutex
+	clients map[chan string]bool
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newReloadHub() *reloadHub {
+	return &reloadHub{
+		clients: map[chan string]bool{},
+		done:    make(chan struct{}),
+	}
+}
+
+func (h *reloadHub) Close() {
+	h.once.Do(func() {
+		close(h.done)
+	})
+}
+
+func (h *reloadHub) Broadcast(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *reloadHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan string, 1)
+	h.mu.Lock()
+	h.clients[ch] = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, ch)
+		h.mu.Unlock()
+	}()
+
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		case event := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+const liveReloadScript = `<script>(function () {
+    var events = new EventSource("/__events");
+    events.onmessage = function (e) { if (e.data === "reload") { window.location.reload(); } };
+})();</script>
+`
+
+func serveHTMLWithSnippets(w http.ResponseWriter, r *http.Request, buildDir string, snippets ...string) bool {
+	page := r.URL.Path
+	if strings.HasSuffix(page, "/") {
+		page += "index.html"
+	}
+	if !strings.HasSuffix(page, ".html") {
+		return false
+	}
+
+	data, err := os.ReadFile(filepath.Join(buildDir, filepath.FromSlash(filepath.Clean("/"+page))))
+	if err != nil {
+		return false
+	}
+
+	if i := bytes.LastIndex(data, []byte("</body>")); i >= 0 {
+		injected := []byte(strings.Join(snippets, ""))
+		data = append(data[:i], append(injected, data[i:]...)...)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+	return true
+}
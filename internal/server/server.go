@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	Addr            string
+	BuildDir        string
+	Root            string
+	Loader          ProjectLoader
+	LiveReload      bool
+	Edit            bool
+	BasicAuth       string
+	TemplatesDir    string
+	OnSave          func(path string)
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	Logger          *log.Logger
+}
+
+type Server struct {
+	opts     Options
+	mux      *http.ServeMux
+	hub      *reloadHub
+	http     *http.Server
+	listener net.Listener
+
+	linksMu sync.Mutex
+	links   map[string]string
+}
+
+func New(opts Options) *Server {
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = 5 * time.Second
+	}
+
+	s := &Server{
+		opts: opts,
+		mux:  http.NewServeMux(),
+	}
+
+	if opts.LiveReload {
+		s.hub = newReloadHub()
+		s.mux.Handle("/__events", s.hub)
+	}
+	if opts.Loader != nil {
+		s.mux.Handle("/api/", NewAPI(opts.Loader, opts.Root))
+		if opts.Edit {
+			s.registerEditor()
+		}
+	}
+	s.mux.HandleFunc("/", s.handleFiles)
+
+	s.http = &http.Server{
+		Addr:         opts.Addr,
+		Handler:      s.Handler(),
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+		IdleTimeout:  opts.IdleTimeout,
+	}
+	if s.hub != nil {
+		s.http.RegisterOnShutdown(s.hub.Close)
+	}
+
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	if s.opts.Logger == nil {
+		return s.mux
+	}
+	return logRequests(s.opts.Logger, s.mux)
+}
+
+func (s *Server) Mux() *http.ServeMux {
+	return s.mux
+}
+
+func (s *Server) Reload() {
+	s.linksMu.Lock()
+	s.links = nil
+	s.linksMu.Unlock()
+
+	if s.hub != nil {
+		s.hub.Broadcast("reload")
+	}
+}
+
+func (s *Server) Listen() (net.Addr, error) {
+	ln, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	s.listener = ln
+	return ln.Addr(), nil
+}
+
+func (s *Server) Serve(ctx context.Context) error {
+	if s.listener == nil {
+		if _, err := s.Listen(); err != nil {
+			return err
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.http.Serve(s.listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+		defer cancel()
+		return s.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	var snippets []string
+	if s.hub != nil {
+		snippets = append(snippets, liveReloadScript)
+	}
+	if s.opts.Edit && s.opts.Loader != nil {
+		snippets = append(snippets, s.editLink(r))
+	}
+	if len(snippets) > 0 && serveHTMLWithSnippets(w, r, s.opts.BuildDir, snippets...) {
+		return
+	}
+	http.FileServer(http.Dir(s.opts.BuildDir)).ServeHTTP(w, r)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func logRequests(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		path := r.URL.Path
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		logger.Printf("%s %s %d %dB %s", r.Method, path, rec.status, rec.bytes, time.Since(start).Round(time.Microsecond))
+	})
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+func newBuildDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html><body>index</body></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func startServer(t *testing.T, s *Server) (string, <-chan error, context.CancelFunc) {
+	t.Helper()
+
+	addr, err := s.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx)
+	}()
+	t.Cleanup(cancel)
+
+	return "http://" + addr.String(), done, cancel
+}
+
+func waitServe(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve не завершился")
+		return nil
+	}
+}
+
+func TestNewAppliesOptions(t *testing.T) {
+	s := New(Options{
+		Addr:         "127.0.0.1:0",
+		ReadTimeout:  time.Second,
+		WriteTimeout: 2 * time.Second,
+		IdleTimeout:  3 * time.Second,
+	})
+
+	if s.http.ReadTimeout != time.Second || s.http.WriteTimeout != 2*time.Second || s.http.IdleTimeout != 3*time.Second {
+		t.Errorf("таймауты не переданы в http.Server: read=%s write=%s idle=%s", s.http.ReadTimeout, s.http.WriteTimeout, s.http.IdleTimeout)
+	}
+	if s.opts.ShutdownTimeout != 5*time.Second {
+		t.Errorf("ShutdownTimeout по умолчанию = %s, ожидалось 5s", s.opts.ShutdownTimeout)
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(Options{BuildDir: newBuildDir(t), Logger: log.New(&buf, "", 0)})
+
+	for _, target := range []string{"/", "/missing.html"} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ожидалось 2 строки лога, получено %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "GET / 200 ") || !strings.Contains(lines[0], "31B") {
+		t.Errorf("неожиданная строка лога: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "GET /missing.html 404 ") {
+		t.Errorf("неожиданная строка лога: %q", lines[1])
+	}
+}
+
+func TestHandlerWithoutLogger(t *testing.T) {
+	s := New(Options{BuildDir: newBuildDir(t)})
+
+	if _, ok := s.Handler().(*http.ServeMux); !ok {
+		t.Errorf("без Logger Handler должен возвращать mux без обёртки, получено %T", s.Handler())
+	}
+}
+
+func TestStatusRecorderUnwrap(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(Options{BuildDir: newBuildDir(t), Logger: log.New(&buf, "", 0)})
+	s.Mux().HandleFunc("/flush", func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush через statusRecorder: %v", err)
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/flush", nil))
+
+	if !strings.HasPrefix(buf.String(), "GET /flush 200 ") {
+		t.Errorf("неожиданная строка лога: %q", buf.String())
+	}
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	s := New(Options{Addr: "127.0.0.1:0", BuildDir: newBuildDir(t), ShutdownTimeout: 5 * time.Second})
+	s.Mux().HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+	url, done, cancel := startServer(t, s)
+
+	type result struct {
+		body string
+		err  error
+	}
+	resp := make(chan result, 1)
+	go func() {
+		r, err := http.Get(url + "/slow")
+		if err != nil {
+			resp <- result{err: err}
+			return
+		}
+		defer r.Body.Close()
+		body, err := io.ReadAll(r.Body)
+		resp <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Serve завершился до окончания активного запроса: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := waitServe(t, done); err != nil {
+		t.Errorf("Serve вернул ошибку: %v", err)
+	}
+
+	r := <-resp
+	if r.err != nil || r.body != "done" {
+		t.Errorf("активный запрос не завершился: body=%q err=%v", r.body, r.err)
+	}
+
+	if _, err := http.Get(url + "/"); err == nil {
+		t.Error("после остановки сервер продолжает принимать соединения")
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	s := New(Options{Addr: "127.0.0.1:0", BuildDir: newBuildDir(t), ShutdownTimeout: 50 * time.Millisecond})
+	s.Mux().HandleFunc("/hang", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	url, done, cancel := startServer(t, s)
+
+	go http.Get(url + "/hang")
+	<-started
+	cancel()
+
+	if err := waitServe(t, done); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ожидалась ошибка context.DeadlineExceeded, получено %v", err)
+	}
+}
+
+func TestServeClosesLiveReloadOnShutdown(t *testing.T) {
+	s := New(Options{Addr: "127.0.0.1:0", BuildDir: newBuildDir(t), LiveReload: true, ShutdownTimeout: 5 * time.Second})
+	url, done, cancel := startServer(t, s)
+
+	resp, err := http.Get(url + "/__events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("не получено приветствие SSE: %q, %v", line, err)
+	}
+
+	s.Reload()
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("не получено событие reload: %v", err)
+		}
+		if line == "data: reload\n" {
+			break
+		}
+	}
+
+	cancel()
+	if err := waitServe(t, done); err != nil {
+		t.Errorf("Serve с открытым SSE-соединением вернул ошибку: %v", err)
+	}
+}
+
+func TestReadTimeoutClosesSlowClient(t *testing.T) {
+	s := New(Options{Addr: "127.0.0.1:0", BuildDir: newBuildDir(t), ReadTimeout: 100 * time.Millisecond})
+	url, _, _ := startServer(t, s)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("соединение не закрыто сервером: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("соединение закрыто через %s, ожидалось около ReadTimeout", elapsed)
+	}
+}
+
+func TestIndexServedWithLiveReload(t *testing.T) {
+	s := New(Options{BuildDir: newBuildDir(t), LiveReload: true})
+
+	for _, target := range []string{"/", "/index.html"} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `new EventSource("/__events")`) {
+			t.Errorf("GET %s: статус %d, скрипт live-reload не внедрён: %q", target, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestEditLinkSameBasenames(t *testing.T) {
+	root := t.TempDir()
+	writeSpec(t, filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Users](users/get.md)\n- [Orders](orders/get.md)\n")
+	writeSpec(t, filepath.Join(root, "specs", "users", "get.md"), "# Users\n")
+	writeSpec(t, filepath.Join(root, "specs", "orders", "get.md"), "# Orders\n")
+
+	build := newBuildDir(t)
+	for _, page := range []string{"specs__users__get.md.html", "specs__orders__get.md.html"} {
+		if err := os.WriteFile(filepath.Join(build, page), []byte("<html><body></body></html>"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load := func() (*spec.Project, error) {
+		return spec.LoadProject([]string{filepath.Join(root, "specs", "api.md")}, spec.DiscoverOptions{Root: root}, nil)
+	}
+	s := New(Options{BuildDir: build, Root: root, Loader: load, Edit: true})
+
+	for page, want := range map[string]string{
+		"/specs__users__get.md.html":  `href="/edit/specs/users/get.md"`,
+		"/specs__orders__get.md.html": `href="/edit/specs/orders/get.md"`,
+	} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, page, nil))
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET %s: нет ссылки %s: %q", page, want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package spec
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+type Cache struct {
+	mu      sync.Mutex
+	names   *SectionNames
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	once sync.Once
+	spec *Spec
+	err  error
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: map[string]*cacheEntry{}}
+}
+
+func (c *Cache) ParseFile(path string) (*Spec, error) {
+	if c == nil {
+		return ParseFile(path)
+	}
+
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	names := c.names
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.spec, entry.err = ParseFileWith(key, names)
+	})
+
+	return entry.spec, entry.err
+}
+
+func (c *Cache) SectionNames() *SectionNames {
+	if c == nil {
+		return defaultSectionNames
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return sectionNamesOrDefault(c.names)
+}
+
+func (c *Cache) SetSectionNames(names *SectionNames) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.names.equal(names) {
+		return
+	}
+	c.names = names
+	c.entries = map[string]*cacheEntry{}
+}
+
+func (c *Cache) ParseDependencies(path string) (*Spec, []Edge, error) {
+	spec, err := c.ParseFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return spec, dependencyEdges(path, spec), nil
+}
+
+func (c *Cache) Invalidate(paths ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, path := range paths {
+		key, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		delete(c.entries, key)
+	}
+}
+
+func (c *Cache) Clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*cacheEntry{}
+}
+
+func (c *Cache) Preload(paths []string, workers int) {
+	_ = forEach(len(paths), workers, func(i int) error {
+		_, _ = c.ParseFile(paths[i])
+		return nil
+	})
+}
+
+func forEach(n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
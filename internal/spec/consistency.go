@@ -0,0 +1,76 @@
+package spec
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+func checkFlowDependencies(spec *Spec) []Diagnostic {
+	if !spec.HasSection(SectionDependencies) || !spec.HasSection(SectionFlow) {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+
+	declared := map[string]bool{}
+	for _, dep := range spec.Dependencies {
+		if dep.Path != "" {
+			declared[dependencyKey(dep.Path)] = true
+		}
+	}
+
+	used := map[string]bool{}
+	for _, step := range spec.Flow {
+		for _, call := range step.Calls {
+			key := dependencyKey(call.Path)
+			if used[key] {
+				continue
+			}
+			used[key] = true
+
+			if declared[key] {
+				continue
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "undeclared-dependency",
+				Severity: SeverityError,
+				File:     spec.Path,
+				Line:     call.Range.Start.Line,
+				Column:   call.Range.Start.Column,
+				End:      call.Range.End,
+				Message:  fmt.Sprintf("%s используется во Flow, но не указана в Dependencies", call.Path),
+				Fix:      "добавьте спецификацию в Dependencies или запустите spec-agent fmt",
+			})
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, dep := range spec.Dependencies {
+		if dep.Path == "" {
+			continue
+		}
+		key := dependencyKey(dep.Path)
+		if used[key] || reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		diagnostics = append(diagnostics, Diagnostic{
+			RuleID:   "unused-dependency",
+			Severity: SeverityWarning,
+			File:     spec.Path,
+			Line:     dep.Range.Start.Line,
+			Column:   dep.Range.Start.Column,
+			End:      dep.Range.End,
+			Message:  fmt.Sprintf("%s указана в Dependencies, но не используется во Flow", dep.Path),
+			Fix:      "удалите зависимость или добавьте шаг Flow, который её вызывает",
+		})
+	}
+
+	return diagnostics
+}
+
+func dependencyKey(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
@@ -0,0 +1,211 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type ContextOptions struct {
+	Prompt    string
+	Rules     string
+	MaxTokens int
+	WithCode  bool
+}
+
+type ContextSpec struct {
+	Path    string       `json:"path"`
+	Depth   int          `json:"depth"`
+	Tokens  int          `json:"tokens"`
+	Content string       `json:"content"`
+	Code    *ContextCode `json:"code,omitempty"`
+}
+
+type ContextCode struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type ContextBundle struct {
+	Entry   string        `json:"entry"`
+	Prompt  string        `json:"prompt,omitempty"`
+	Rules   string        `json:"rules,omitempty"`
+	Specs   []ContextSpec `json:"specs"`
+	Omitted []string      `json:"omitted,omitempty"`
+	Missing []string      `json:"missing,omitempty"`
+	Tokens  int           `json:"tokens"`
+}
+
+func BuildContext(entry string, cache *Cache, opts ContextOptions) (*ContextBundle, error) {
+	if cache == nil {
+		cache = NewCache()
+	}
+
+	entry, err := filepath.Abs(entry)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cache.ParseFile(entry); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать спецификацию: %w", err)
+	}
+
+	bundle := &ContextBundle{
+		Entry:  RelPath(entry),
+		Prompt: opts.Prompt,
+		Rules:  opts.Rules,
+	}
+
+	depths := map[string]int{entry: 0}
+	items := map[string]ContextSpec{}
+	children := map[string][]string{}
+	queue := []string{entry}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		s, edges, err := cache.ParseDependencies(current)
+		if err != nil {
+			bundle.Missing = append(bundle.Missing, RelPath(current))
+			continue
+		}
+
+		item := ContextSpec{
+			Path:    RelPath(current),
+			Depth:   depths[current],
+			Content: s.Content,
+		}
+		if opts.WithCode {
+			item.Code = pairedCode(current)
+		}
+		item.Tokens = EstimateTokens(item.Content)
+		if item.Code != nil {
+			item.Tokens += EstimateTokens(item.Code.Content)
+		}
+		items[current] = item
+
+		for _, edge := range edges {
+			children[current] = append(children[current], edge.To)
+			if _, seen := depths[edge.To]; seen {
+				continue
+			}
+			depths[edge.To] = depths[current] + 1
+			queue = append(queue, edge.To)
+		}
+	}
+
+	visited := map[string]bool{}
+	var visit func(path string)
+	visit = func(path string) {
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		for _, child := range children[path] {
+			visit(child)
+		}
+		if item, ok := items[path]; ok {
+			bundle.Specs = append(bundle.Specs, item)
+		}
+	}
+	visit(entry)
+	sort.Strings(bundle.Missing)
+
+	bundle.Tokens = EstimateTokens(bundle.Prompt) + EstimateTokens(bundle.Rules)
+	for _, item := range bundle.Specs {
+		bundle.Tokens += item.Tokens
+	}
+
+	if opts.MaxTokens > 0 {
+		for bundle.Tokens > opts.MaxTokens && len(bundle.Specs) > 1 {
+			deepest := 0
+			for i, item := range bundle.Specs[:len(bundle.Specs)-1] {
+				if d := bundle.Specs[deepest]; item.Depth > d.Depth || item.Depth == d.Depth && item.Path > d.Path {
+					deepest = i
+				}
+			}
+			omitted := bundle.Specs[deepest]
+			bundle.Specs = append(bundle.Specs[:deepest], bundle.Specs[deepest+1:]...)
+			bundle.Omitted = append(bundle.Omitted, omitted.Path)
+			bundle.Tokens -= omitted.Tokens
+		}
+		sort.Strings(bundle.Omitted)
+	}
+
+	return bundle, nil
+}
+
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+func (b *ContextBundle) Markdown() string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "# Context: %s\n\n", b.Entry)
+
+	if b.Prompt != "" {
+		out.WriteString("## Agent Prompt\n\n")
+		out.WriteString(strings.TrimSpace(b.Prompt))
+		out.WriteString("\n\n")
+	}
+
+	if b.Rules != "" {
+		out.WriteString("## Specification Rules\n\n")
+		out.WriteString(strings.TrimSpace(b.Rules))
+		out.WriteString("\n\n")
+	}
+
+	out.WriteString("## Specifications\n\n")
+	for _, item := range b.Specs {
+		fmt.Fprintf(&out, "### %s\n\n", item.Path)
+		writeFenced(&out, "markdown", item.Content)
+
+		if item.Code != nil {
+			fmt.Fprintf(&out, "Code: %s\n\n", item.Code.Path)
+			writeFenced(&out, "go", item.Code.Content)
+		}
+	}
+
+	if len(b.Omitted) > 0 {
+		out.WriteString("## Omitted\n\n")
+		out.WriteString("Not included because of the token budget, read them if needed:\n\n")
+		for _, path := range b.Omitted {
+			fmt.Fprintf(&out, "- %s\n", path)
+		}
+		out.WriteString("\n")
+	}
+
+	if len(b.Missing) > 0 {
+		out.WriteString("## Missing\n\n")
+		out.WriteString("Referenced but not found:\n\n")
+		for _, path := range b.Missing {
+			fmt.Fprintf(&out, "- %s\n", path)
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+func pairedCode(specPath string) *ContextCode {
+	codePath := strings.TrimSuffix(specPath, ".md") + ".go"
+	data, err := os.ReadFile(codePath)
+	if err != nil {
+		return nil
+	}
+	return &ContextCode{Path: RelPath(codePath), Content: string(data)}
+}
+
+func writeFenced(out *strings.Builder, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	content = strings.TrimRight(content, "\n")
+	fmt.Fprintf(out, "%s%s\n%s\n%s\n\n", fence, lang, content, fence)
+}
@@ -0,0 +1,82 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeContextSpecs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func contextPaths(specs []ContextSpec) []string {
+	paths := make([]string, 0, len(specs))
+	for _, item := range specs {
+		paths = append(paths, filepath.Base(item.Path))
+	}
+	return paths
+}
+
+func TestBuildContextDependencyOrder(t *testing.T) {
+	root := writeContextSpecs(t, map[string]string{
+		"api.md":     "# API\n\n## Dependencies\n- [Service](service.md)\n- [Cache](cache.md)\n",
+		"service.md": "# Service\n\n## Dependencies\n- [Repo](repo.md)\n- [Cache](cache.md)\n",
+		"repo.md":    "# Repo\n",
+		"cache.md":   "# Cache\n",
+	})
+
+	bundle, err := BuildContext(filepath.Join(root, "api.md"), nil, ContextOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := contextPaths(bundle.Specs), []string{"repo.md", "cache.md", "service.md", "api.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("порядок %v, ожидался %v (зависимости раньше зависящих)", got, want)
+	}
+
+	depths := map[string]int{}
+	for _, item := range bundle.Specs {
+		depths[filepath.Base(item.Path)] = item.Depth
+	}
+	if want := map[string]int{"api.md": 0, "service.md": 1, "cache.md": 1, "repo.md": 2}; !reflect.DeepEqual(depths, want) {
+		t.Errorf("глубины %v, ожидались %v", depths, want)
+	}
+
+	budget := bundle.Tokens - bundle.Specs[0].Tokens
+	trimmed, err := BuildContext(filepath.Join(root, "api.md"), nil, ContextOptions{MaxTokens: budget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := contextPaths(trimmed.Specs), []string{"cache.md", "service.md", "api.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("после бюджета %v, ожидался %v (отбрасывается самая глубокая спека)", got, want)
+	}
+	if len(trimmed.Omitted) != 1 || filepath.Base(trimmed.Omitted[0]) != "repo.md" {
+		t.Errorf("Omitted = %v, ожидался repo.md", trimmed.Omitted)
+	}
+}
+
+func TestBuildContextCycle(t *testing.T) {
+	root := writeContextSpecs(t, map[string]string{
+		"a.md": "# A\n\n## Dependencies\n- [B](b.md)\n",
+		"b.md": "# B\n\n## Dependencies\n- [A](a.md)\n- [Gone](gone.md)\n",
+	})
+
+	bundle, err := BuildContext(filepath.Join(root, "a.md"), nil, ContextOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := contextPaths(bundle.Specs), []string{"b.md", "a.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("порядок при цикле %v, ожидался %v (входная спека последней)", got, want)
+	}
+	if len(bundle.Missing) != 1 || filepath.Base(bundle.Missing[0]) != "gone.md" {
+		t.Errorf("Missing = %v, ожидался gone.md", bundle.Missing)
+	}
+}
@@ -0,0 +1,205 @@
+package spec
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	diagramWidth      = 780
+	diagramNodeWidth  = 220
+	diagramNodeHeight = 36
+	diagramRowStep    = 52
+	diagramPadding    = 20
+	diagramLabelLimit = 26
+)
+
+var edgeKindColors = map[string]string{
+	"calls":     "#667eea",
+	"reads":     "#2f9e44",
+	"writes":    "#e8590c",
+	"uses":      "#495057",
+	"validates": "#ae3ec9",
+	"depends":   "#adb5bd",
+}
+
+type neighbour struct {
+	Path  string
+	Kinds []string
+}
+
+func generateNeighbourhoodSVG(specPath string, graph *Graph, specs map[string]*Spec, root string) string {
+	callers, callees := collectNeighbours(specPath, graph)
+	if len(callers) == 0 && len(callees) == 0 {
+		return ""
+	}
+
+	rows := len(callers)
+	if len(callees) > rows {
+		rows = len(callees)
+	}
+	height := rows*diagramRowStep + 2*diagramPadding
+
+	leftX := diagramPadding
+	centerX := (diagramWidth - diagramNodeWidth) / 2
+	rightX := diagramWidth - diagramPadding - diagramNodeWidth
+	centerY := (height - diagramNodeHeight) / 2
+
+	id := svgIDPrefix(specPath)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="spec-diagram" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="Граф связей">
+`, diagramWidth, height)
+	b.WriteString("<defs>\n")
+	for _, kind := range sortedKindNames() {
+		fmt.Fprintf(&b, `<marker id="%s-arrow-%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="%s"/></marker>
+`, id, kind, edgeKindColors[kind])
+	}
+	b.WriteString("</defs>\n")
+
+	for i, n := range callers {
+		y := rowY(i, len(callers), height)
+		writeDiagramEdge(&b, id, leftX+diagramNodeWidth, y+diagramNodeHeight/2, centerX, centerY+diagramNodeHeight/2, n.Kinds)
+	}
+	for i, n := range callees {
+		y := rowY(i, len(callees), height)
+		writeDiagramEdge(&b, id, centerX+diagramNodeWidth, centerY+diagramNodeHeight/2, rightX, y+diagramNodeHeight/2, n.Kinds)
+	}
+
+	for i, n := range callers {
+		writeDiagramNode(&b, n.Path, leftX, rowY(i, len(callers), height), specs, root, false)
+	}
+	writeDiagramNode(&b, specPath, centerX, centerY, specs, root, true)
+	for i, n := range callees {
+		writeDiagramNode(&b, n.Path, rightX, rowY(i, len(callees), height), specs, root, false)
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func collectNeighbours(specPath string, graph *Graph) ([]neighbour, []neighbour) {
+	callerKinds := map[string]map[string]bool{}
+	calleeKinds := map[string]map[string]bool{}
+
+	for _, edge := range graph.Edges {
+		if edge.From == edge.To {
+			continue
+		}
+		for _, kind := range edge.Kinds {
+			if edge.To == specPath {
+				addKind(callerKinds, edge.From, kind)
+			}
+			if edge.From == specPath {
+				addKind(calleeKinds, edge.To, kind)
+			}
+		}
+	}
+
+	return toNeighbours(callerKinds), toNeighbours(calleeKinds)
+}
+
+func addKind(m map[string]map[string]bool, path, kind string) {
+	if kind == "" {
+		kind = "depends"
+	}
+	if m[path] == nil {
+		m[path] = map[string]bool{}
+	}
+	m[path][kind] = true
+}
+
+func toNeighbours(m map[string]map[string]bool) []neighbour {
+	result := make([]neighbour, 0, len(m))
+	for path, kinds := range m {
+		n := neighbour{Path: path}
+		for kind := range kinds {
+			if kind == "depends" && len(kinds) > 1 {
+				continue
+			}
+			n.Kinds = append(n.Kinds, kind)
+		}
+		sort.Strings(n.Kinds)
+		result = append(result, n)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+func sortedKindNames() []string {
+	kinds := make([]string, 0, len(edgeKindColors))
+	for kind := range edgeKindColors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func rowY(i, count, height int) int {
+	total := count*diagramRowStep - (diagramRowStep - diagramNodeHeight)
+	top := (height - total) / 2
+	return top + i*diagramRowStep
+}
+
+func writeDiagramEdge(b *strings.Builder, id string, x1, y1, x2, y2 int, kinds []string) {
+	color := edgeKindColors[kinds[0]]
+	if color == "" {
+		color = edgeKindColors["depends"]
+	}
+	marker := kinds[0]
+	if _, ok := edgeKindColors[marker]; !ok {
+		marker = "depends"
+	}
+
+	fmt.Fprintf(b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="1.5" marker-end="url(#%s-arrow-%s)"/>
+`, x1, y1, x2, y2, color, id, marker)
+	fmt.Fprintf(b, `<text x="%d" y="%d" font-size="11" text-anchor="middle" fill="%s">%s</text>
+`, (x1+x2)/2, (y1+y2)/2-4, color, html.EscapeString(strings.Join(kinds, ", ")))
+}
+
+func writeDiagramNode(b *strings.Builder, path string, x, y int, specs map[string]*Spec, root string, current bool) {
+	class, fill, stroke, text, dash := "node", "#ffffff", "#667eea", "#333333", ""
+	if current {
+		class, fill, stroke, text = "node node-current", "#667eea", "#764ba2", "#ffffff"
+	}
+
+	_, exported := specs[path]
+	if !exported && !current {
+		class, stroke, text, dash = "node node-missing", "#adb5bd", "#868e96", ` stroke-dasharray="4 3"`
+	}
+
+	label := diagramLabel(path, specs)
+
+	if exported && !current {
+		fmt.Fprintf(b, `<a href="%s">`, html.EscapeString(PageFilename(root, path)))
+	}
+	fmt.Fprintf(b, `<g class="%s"><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="%s" stroke-width="1.5"%s/>`,
+		class, html.EscapeString(filepath.Base(path)), x, y, diagramNodeWidth, diagramNodeHeight, fill, stroke, dash)
+	fmt.Fprintf(b, `<text x="%d" y="%d" font-size="13" text-anchor="middle" fill="%s">%s</text></g>`,
+		x+diagramNodeWidth/2, y+diagramNodeHeight/2+4, text, html.EscapeString(label))
+	if exported && !current {
+		b.WriteString("</a>")
+	}
+	b.WriteString("\n")
+}
+
+func svgIDPrefix(seed string) string {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	return fmt.Sprintf("sa%08x", h.Sum32())
+}
+
+func diagramLabel(path string, specs map[string]*Spec) string {
+	label := filepath.Base(path)
+	if spec, ok := specs[path]; ok && spec.Title != "" {
+		label = spec.Title
+	}
+
+	return truncateLabel(label, diagramLabelLimit)
+}
@@ -0,0 +1,58 @@
+package spec
+
+import (
+	"regexp"
+	"testing"
+)
+
+var (
+	svgIDRe  = regexp.MustCompile(`<marker id="([^"]+)"`)
+	svgRefRe = regexp.MustCompile(`url\(#([^)]+)\)`)
+)
+
+func assertOwnMarkers(t *testing.T, name, svg string, seen map[string]string) {
+	t.Helper()
+
+	ids := map[string]bool{}
+	for _, m := range svgIDRe.FindAllStringSubmatch(svg, -1) {
+		if other, ok := seen[m[1]]; ok {
+			t.Errorf("%s: id маркера %q уже использован в %s", name, m[1], other)
+		}
+		seen[m[1]] = name
+		ids[m[1]] = true
+	}
+	if len(ids) == 0 {
+		t.Fatalf("%s: нет маркеров в SVG", name)
+	}
+	for _, m := range svgRefRe.FindAllStringSubmatch(svg, -1) {
+		if !ids[m[1]] {
+			t.Errorf("%s: ссылка на маркер %q вне своей диаграммы", name, m[1])
+		}
+	}
+}
+
+func TestDiagramMarkerIDsUnique(t *testing.T) {
+	graph := &Graph{Edges: []Edge{
+		{From: "/specs/api.md", To: "/specs/service.md", Kinds: []string{"calls"}},
+		{From: "/specs/service.md", To: "/specs/repo.md", Kinds: []string{"reads"}},
+	}}
+
+	seen := map[string]string{}
+	for _, path := range []string{"/specs/api.md", "/specs/service.md", "/specs/repo.md"} {
+		assertOwnMarkers(t, path, generateNeighbourhoodSVG(path, graph, nil, "/"), seen)
+	}
+
+	sequences := map[string]*Sequence{
+		"api": {
+			Participants: []Participant{{ID: "api", Path: "/specs/api.md"}, {ID: "service", Path: "/specs/service.md"}},
+			Messages:     []SequenceMessage{{From: "api", To: "service", Label: "вызов"}, {From: "service", To: "service", Label: "сам себе"}},
+		},
+		"service": {
+			Participants: []Participant{{ID: "service", Path: "/specs/service.md"}, {ID: "repo", Path: "/specs/repo.md"}},
+			Messages:     []SequenceMessage{{From: "service", To: "repo", Label: "чтение"}},
+		},
+	}
+	for name, seq := range sequences {
+		assertOwnMarkers(t, "sequence "+name, seq.SVG(), seen)
+	}
+}
@@ -0,0 +1,298 @@
+package spec
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var DefaultInclude = []string{"**/*.md"}
+
+var DefaultExclude = []string{
+	"README.md",
+	"readme.md",
+	"CHANGELOG.md",
+	"changelog.md",
+	"**/.spec_agent/**",
+	"**/spec_changes/**",
+	"**/.git/**",
+	"**/node_modules/**",
+	"**/vendor/**",
+}
+
+type DiscoverOptions struct {
+	Root             string
+	Include          []string
+	Exclude          []string
+	NoDefaultExclude bool
+	NoGitignore      bool
+}
+
+type DiscoveredFile struct {
+	Path     string
+	Dir      bool
+	Included bool
+	Reason   string
+}
+
+type ignoreRule struct {
+	source   string
+	raw      string
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func FindSpecs(roots []string, opts DiscoverOptions) ([]string, error) {
+	files, err := Discover(roots, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []string
+	for _, f := range files {
+		if f.Included {
+			specs = append(specs, f.Path)
+		}
+	}
+	return specs, nil
+}
+
+func Discover(roots []string, opts DiscoverOptions) ([]DiscoveredFile, error) {
+	root := opts.Root
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		root = wd
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	include := opts.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+
+	var rules []ignoreRule
+	loaded := map[string]bool{}
+	loadGitignore := func(dir string) {
+		if opts.NoGitignore || loaded[dir] {
+			return
+		}
+		loaded[dir] = true
+		rules = append(rules, readGitignore(root, filepath.Join(dir, ".gitignore"))...)
+	}
+
+	var result []DiscoveredFile
+	seen := map[string]bool{}
+
+	for _, start := range roots {
+		start, err := filepath.Abs(start)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dir := range parentDirs(root, start) {
+			loadGitignore(dir)
+		}
+
+		err = filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if seen[p] {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			seen[p] = true
+
+			rel := slashRel(root, p)
+
+			if d.IsDir() {
+				if reason := skipDirReason(rel, opts, rules); reason != "" && p != start {
+					result = append(result, DiscoveredFile{Path: p, Dir: true, Reason: reason})
+					return filepath.SkipDir
+				}
+				loadGitignore(p)
+				return nil
+			}
+
+			file := DiscoveredFile{Path: p}
+			matched := matchAny(include, rel) != ""
+			if !matched && filepath.Ext(p) != ".md" {
+				return nil
+			}
+
+			switch {
+			case !matched:
+				file.Reason = "не совпадает с include"
+			case !opts.NoDefaultExclude && matchAny(DefaultExclude, rel) != "":
+				file.Reason = "исключён по умолчанию: " + matchAny(DefaultExclude, rel)
+			case matchAny(opts.Exclude, rel) != "":
+				file.Reason = "исключён правилом exclude: " + matchAny(opts.Exclude, rel)
+			default:
+				if rule := ignoredBy(rules, rel, false); rule != nil {
+					file.Reason = fmt.Sprintf("игнорируется %s: %s", rule.source, rule.raw)
+				} else {
+					file.Included = true
+				}
+			}
+
+			result = append(result, file)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, nil
+}
+
+func skipDirReason(rel string, opts DiscoverOptions, rules []ignoreRule) string {
+	probe := rel + "/_"
+	if !opts.NoDefaultExclude {
+		if pattern := matchAny(DefaultExclude, probe); strings.HasSuffix(pattern, "/**") {
+			return "исключён по умолчанию: " + pattern
+		}
+	}
+	if pattern := matchAny(opts.Exclude, probe); strings.HasSuffix(pattern, "/**") {
+		return "исключён правилом exclude: " + pattern
+	}
+	if rule := ignoredBy(rules, rel, true); rule != nil {
+		return fmt.Sprintf("игнорируется %s: %s", rule.source, rule.raw)
+	}
+	return ""
+}
+
+func matchAny(patterns []string, rel string) string {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, rel) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+func parentDirs(root, dir string) []string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	dirs := []string{root}
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		dirs = append(dirs, current)
+	}
+	return dirs[:len(dirs)-1]
+}
+
+func slashRel(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func readGitignore(root, file string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	base := slashRel(root, filepath.Dir(file))
+	if base == "." {
+		base = ""
+	}
+	source := slashRel(root, file)
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{source: source, base: base, raw: line}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		rule.pattern = line
+		if line != "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func ignoredBy(rules []ignoreRule, rel string, isDir bool) *ignoreRule {
+	var match *ignoreRule
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		sub := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, rule.base+"/")
+		}
+
+		var ok bool
+		if rule.anchored {
+			ok = matchSegments(strings.Split(rule.pattern, "/"), strings.Split(sub, "/"))
+		} else {
+			ok, _ = path.Match(rule.pattern, path.Base(sub))
+		}
+		if !ok {
+			continue
+		}
+
+		if rule.negate {
+			match = nil
+		} else {
+			match = rule
+		}
+	}
+
+	return match
+}
@@ -1,336 +1,293 @@
 package spec
 
 import (
+	"bytes"
 	"fmt"
 	"html"
+	"html/template"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	agentfs "github.com/SmirnovND/spec-agent/internal/fs"
 )
 
-func ExportToHTML(graph *Graph, outputDir string) error {
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("не удалось создать директорию: %w", err)
+const exportSequenceDepth = 1
+
+type ExportOptions struct {
+	Root         string
+	OutputDir    string
+	TemplatesDir string
+	Theme        string
+	Cache        *Cache
+	Workers      int
+	Force        bool
+}
+
+type PageData struct {
+	Site  SiteData
+	Specs []PageLink
+	Graph *Graph
+	Page  *SpecPage
+}
+
+type SiteData struct {
+	Title       string
+	Description string
+	Theme       string
+}
+
+type PageLink struct {
+	Title string
+	URL   string
+	Path  string
+}
+
+type SpecPage struct {
+	Spec            *Spec
+	Title           string
+	URL             string
+	ContentHTML     template.HTML
+	DiagramSVG      template.HTML
+	SequenceSVG     template.HTML
+	SequenceMermaid string
+	Links           []PageLink
+	Backlinks       []PageLink
+	Metadata        SpecMetadata
+}
+
+type SpecMetadata struct {
+	Path    string
+	File    string
+	Callers int
+	Callees int
+}
+
+func ExportToHTML(graph *Graph, opts ExportOptions) (*ExportResult, error) {
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию: %w", err)
 	}
 
-	specs := make(map[string]*Spec)
+	if opts.Theme == "" {
+		opts.Theme = "light"
+	}
+	if opts.Cache == nil {
+		opts.Cache = NewCache()
+	}
+
+	specs := parseGraphSpecs(graph, opts.Cache, opts.Workers)
+
+	templates := agentfs.Templates(opts.TemplatesDir)
+	pages, err := parsePageTemplates(templates)
+	if err != nil {
+		return nil, err
+	}
+
+	out := newBuildWriter(opts.OutputDir, opts.Force)
+
+	if err := writeStaticAssets(out, templates, opts.Theme); err != nil {
+		return nil, err
+	}
+
+	if err := writeSearchAssets(out, specs, opts.Root); err != nil {
+		return nil, err
+	}
+
+	data := PageData{
+		Site: SiteData{
+			Title:       "Спецификации архитектуры",
+			Description: "Документация компонентов и их взаимодействия",
+			Theme:       opts.Theme,
+		},
+		Specs: specIndex(specs, opts.Root),
+		Graph: graph,
+	}
+
+	if err := renderPage(out, pages["index.html"], data, "index.html"); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(specs))
+	for path := range specs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	err = forEach(len(paths), opts.Workers, func(i int) error {
+		page := data
+		page.Page = buildSpecPage(specs[paths[i]], specs, graph, opts.Cache, opts.Root)
+		return renderPage(out, pages["spec.html"], page, PageFilename(opts.Root, paths[i]))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Finish()
+}
+
+func parseGraphSpecs(graph *Graph, cache *Cache, workers int) map[string]*Spec {
+	paths := make([]string, 0, len(graph.Nodes))
 	for _, node := range graph.Nodes {
-		spec, err := ParseFile(node.Path)
+		paths = append(paths, node.Path)
+	}
+
+	cache.Preload(paths, workers)
+
+	specs := make(map[string]*Spec)
+	for _, path := range paths {
+		spec, err := cache.ParseFile(path)
 		if err != nil {
 			continue
 		}
-		specs[node.Path] = spec
+		specs[path] = spec
 	}
 
-	indexHTML := generateIndexHTML(specs, graph)
-	if err := os.WriteFile(filepath.Join(outputDir, "index.html"), []byte(indexHTML), 0644); err != nil {
-		return fmt.Errorf("не удалось записать index.html: %w", err)
+	return specs
+}
+
+func parsePageTemplates(templates fs.FS) (map[string]*template.Template, error) {
+	pages := map[string]*template.Template{}
+
+	for _, name := range []string{"index.html", "spec.html"} {
+		tmpl, err := template.New(name).ParseFS(templates, name, "partials.html")
+		if err != nil {
+			return nil, fmt.Errorf("не удалось разобрать шаблон %s: %w", name, err)
+		}
+		pages[name] = tmpl
 	}
 
-	for path, spec := range specs {
-		filename := generateFilename(path)
-		specHTML := generateSpecHTML(spec, specs)
-		if err := os.WriteFile(filepath.Join(outputDir, filename), []byte(specHTML), 0644); err != nil {
-			return fmt.Errorf("не удалось записать %s: %w", filename, err)
+	return pages, nil
+}
+
+func writeStaticAssets(out *buildWriter, templates fs.FS, theme string) error {
+	assets := map[string]string{
+		"style.css": "style.css",
+		"search.js": "search.js",
+		"theme.css": "themes/" + theme + ".css",
+	}
+
+	for dest, src := range assets {
+		data, err := fs.ReadFile(templates, src)
+		if err != nil {
+			if dest == "theme.css" {
+				return fmt.Errorf("неизвестная тема %q: %w", theme, err)
+			}
+			return fmt.Errorf("не удалось прочитать %s: %w", src, err)
+		}
+		if err := out.Write(dest, data); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func generateIndexHTML(specs map[string]*Spec, graph *Graph) string {
-	toc := ""
-	for path, spec := range specs {
-		if spec.Title == "" {
-			spec.Title = filepath.Base(path)
-		}
-		filename := generateFilename(path)
-		toc += fmt.Sprintf(`    <li><a href="%s">%s</a></li>
-`, filename, html.EscapeString(spec.Title))
+func renderPage(out *buildWriter, tmpl *template.Template, data PageData, name string) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("не удалось отрисовать %s: %w", name, err)
 	}
 
-	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="ru">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Спецификации архитектуры</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', 'Oxygen', 'Ubuntu', 'Cantarell', sans-serif;
-            line-height: 1.6;
-            color: #333;
-            background: #f5f5f5;
-        }
-        .container {
-            max-width: 1200px;
-            margin: 0 auto;
-            padding: 40px 20px;
-        }
-        header {
-            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
-            color: white;
-            padding: 40px 20px;
-            border-radius: 8px;
-            margin-bottom: 40px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        header h1 {
-            font-size: 2.5em;
-            margin-bottom: 10px;
-        }
-        header p {
-            font-size: 1.1em;
-            opacity: 0.95;
-        }
-        .main-content {
-            display: grid;
-            grid-template-columns: 250px 1fr;
-            gap: 40px;
-        }
-        .sidebar {
-            background: white;
-            padding: 20px;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px rgba(0, 0, 0, 0.05);
-            height: fit-content;
-            position: sticky;
-            top: 20px;
-        }
-        .sidebar h2 {
-            font-size: 1.2em;
-            margin-bottom: 20px;
-            color: #667eea;
-            border-bottom: 2px solid #667eea;
-            padding-bottom: 10px;
-        }
-        .sidebar ul {
-            list-style: none;
-        }
-        .sidebar li {
-            margin-bottom: 10px;
-        }
-        .sidebar a {
-            color: #667eea;
-            text-decoration: none;
-            padding: 8px 12px;
-            display: block;
-            border-radius: 4px;
-            transition: all 0.2s;
-        }
-        .sidebar a:hover {
-            background: #f0f0f0;
-            transform: translateX(4px);
-        }
-        .content {
-            background: white;
-            padding: 40px;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px rgba(0, 0, 0, 0.05);
-        }
-        .welcome {
-            text-align: center;
-            padding: 60px 20px;
-        }
-        .welcome h2 {
-            font-size: 2em;
-            color: #667eea;
-            margin-bottom: 20px;
-        }
-        .welcome p {
-            font-size: 1.1em;
-            color: #666;
-        }
-        @media (max-width: 768px) {
-            .main-content {
-                grid-template-columns: 1fr;
-            }
-            .sidebar {
-                position: static;
-            }
-            header h1 {
-                font-size: 1.8em;
-            }
-        }
-    </style>
-</head>
-<body>
-    <header>
-        <div class="container">
-            <h1>📚 Спецификации архитектуры</h1>
-            <p>Документация компонентов и их взаимодействия</p>
-        </div>
-    </header>
-    <div class="container">
-        <div class="main-content">
-            <div class="sidebar">
-                <h2>Спецификации</h2>
-                <ul>
-%s
-                </ul>
-            </div>
-            <div class="content">
-                <div class="welcome">
-                    <h2>Добро пожаловать!</h2>
-                    <p>Выберите спецификацию из меню слева для просмотра деталей</p>
-                </div>
-            </div>
-        </div>
-    </div>
-</body>
-</html>`, toc)
+	return out.Write(name, buf.Bytes())
 }
 
-func generateSpecHTML(spec *Spec, allSpecs map[string]*Spec) string {
-	title := spec.Title
-	if title == "" {
-		title = filepath.Base(spec.Path)
+func specTitle(spec *Spec) string {
+	if spec.Title != "" {
+		return spec.Title
 	}
+	return filepath.Base(spec.Path)
+}
 
-	contentHTML := markdownToHTML(spec.Content)
+func specIndex(specs map[string]*Spec, root string) []PageLink {
+	links := make([]PageLink, 0, len(specs))
+	for path, spec := range specs {
+		links = append(links, PageLink{
+			Title: specTitle(spec),
+			URL:   PageFilename(root, path),
+			Path:  path,
+		})
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Title < links[j].Title
+	})
+
+	return links
+}
+
+func buildSpecPage(spec *Spec, allSpecs map[string]*Spec, graph *Graph, cache *Cache, root string) *SpecPage {
+	page := &SpecPage{
+		Spec:        spec,
+		Title:       specTitle(spec),
+		URL:         PageFilename(root, spec.Path),
+		ContentHTML: template.HTML(markdownToHTML(spec.Content)),
+		DiagramSVG:  template.HTML(generateNeighbourhoodSVG(spec.Path, graph, allSpecs, root)),
+	}
 
-	navigation := ""
 	for _, link := range spec.Links {
-		filename := generateFilenameFromRelative(spec.Path, link.Path)
-		navigation += fmt.Sprintf(`        <li><a href="%s">%s</a></li>
-`, filename, html.EscapeString(link.Title))
+		page.Links = append(page.Links, PageLink{
+			Title: link.Title,
+			URL:   PageFilename(root, filepath.Join(filepath.Dir(spec.Path), link.Path)),
+			Path:  link.Path,
+		})
+	}
+
+	callers, callees := collectNeighbours(spec.Path, graph)
+	for _, n := range callers {
+		title := filepath.Base(n.Path)
+		if s, ok := allSpecs[n.Path]; ok {
+			title = specTitle(s)
+		}
+		page.Backlinks = append(page.Backlinks, PageLink{
+			Title: title,
+			URL:   PageFilename(root, n.Path),
+			Path:  n.Path,
+		})
 	}
 
-	navSection := ""
-	if navigation != "" {
-		navSection = fmt.Sprintf(`        <div class="navigation">
-            <h3>Связанные спецификации</h3>
-            <ul>
-%s
-            </ul>
-        </div>
-`, navigation)
+	page.Metadata = SpecMetadata{
+		Path:    spec.Path,
+		File:    filepath.Base(spec.Path),
+		Callers: len(callers),
+		Callees: len(callees),
 	}
 
-	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="ru">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>%s - Спецификации</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', 'Oxygen', 'Ubuntu', 'Cantarell', sans-serif;
-            line-height: 1.8;
-            color: #333;
-            background: #f5f5f5;
-        }
-        .container {
-            max-width: 900px;
-            margin: 0 auto;
-            padding: 40px 20px;
-        }
-        header {
-            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
-            color: white;
-            padding: 30px 20px;
-            margin-bottom: 40px;
-            border-radius: 8px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        header .back-link {
-            display: inline-block;
-            margin-bottom: 15px;
-            color: rgba(255,255,255,0.9);
-            text-decoration: none;
-            font-size: 0.95em;
-            transition: color 0.2s;
-        }
-        header .back-link:hover {
-            color: white;
-        }
-        header h1 {
-            font-size: 2em;
-            margin-bottom: 5px;
-        }
-        .content {
-            background: white;
-            padding: 40px;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px rgba(0, 0, 0, 0.05);
-        }
-        .content h1 { font-size: 2em; margin-top: 30px; margin-bottom: 20px; color: #333; border-bottom: 2px solid #667eea; padding-bottom: 10px; }
-        .content h1:first-child { margin-top: 0; }
-        .content h2 { font-size: 1.5em; margin-top: 25px; margin-bottom: 15px; color: #555; }
-        .content h3 { font-size: 1.2em; margin-top: 20px; margin-bottom: 12px; color: #666; }
-        .content p { margin-bottom: 15px; }
-        .content ul, .content ol { margin-left: 30px; margin-bottom: 15px; }
-        .content li { margin-bottom: 8px; }
-        .content code { background: #f4f4f4; padding: 2px 6px; border-radius: 3px; font-family: 'Monaco', 'Menlo', monospace; font-size: 0.95em; color: #d73a49; }
-        .content pre { background: #f4f4f4; padding: 15px; border-radius: 5px; overflow-x: auto; margin-bottom: 15px; }
-        .content pre code { color: #333; padding: 0; background: none; }
-        .content blockquote { border-left: 4px solid #667eea; padding-left: 15px; margin-left: 0; margin-bottom: 15px; color: #666; font-style: italic; }
-        .content table { width: 100%%; border-collapse: collapse; margin-bottom: 15px; }
-        .content table th, .content table td { border: 1px solid #ddd; padding: 12px; text-align: left; }
-        .content table th { background: #f9f9f9; font-weight: 600; }
-        .navigation {
-            margin-top: 40px;
-            padding-top: 30px;
-            border-top: 2px solid #f0f0f0;
-        }
-        .navigation h3 {
-            color: #667eea;
-            margin-bottom: 15px;
-        }
-        .navigation ul {
-            list-style: none;
-            margin-left: 0;
-        }
-        .navigation li {
-            margin-bottom: 10px;
-        }
-        .navigation a {
-            color: #667eea;
-            text-decoration: none;
-            padding: 8px 12px;
-            display: inline-block;
-            border-radius: 4px;
-            transition: all 0.2s;
-            border: 1px solid #667eea;
-        }
-        .navigation a:hover {
-            background: #667eea;
-            color: white;
-        }
-        @media (max-width: 768px) {
-            .container { padding: 20px 15px; }
-            header h1 { font-size: 1.5em; }
-            .content { padding: 20px; }
-        }
-    </style>
-</head>
-<body>
-    <header>
-        <div class="container">
-            <a href="index.html" class="back-link">← Вернуться к спецификациям</a>
-            <h1>%s</h1>
-        </div>
-    </header>
-    <div class="container">
-        <div class="content">
-            %s
-%s
-        </div>
-    </div>
-</body>
-</html>`, html.EscapeString(title), html.EscapeString(title), contentHTML, navSection)
+	parse := func(path string) (*Spec, error) {
+		if s, ok := allSpecs[path]; ok {
+			return s, nil
+		}
+		return cache.ParseFile(path)
+	}
+
+	if seq, err := buildSequence(spec.Path, exportSequenceDepth, parse); err == nil && len(seq.Messages) > 0 {
+		page.SequenceSVG = template.HTML(seq.SVG())
+		page.SequenceMermaid = seq.Mermaid()
+	}
+
+	return page
 }
 
-func generateFilename(path string) string {
-	return filepath.Base(path) + ".html"
+func PageFilename(root, path string) string {
+	rel := RelPath(path)
+	if root != "" {
+		if r, err := filepath.Rel(root, path); err == nil {
+			rel = r
+		}
+	}
+
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(rel)), "/")
+	for i, part := range parts {
+		if part == ".." {
+			parts[i] = "up"
+		}
+	}
+	return strings.Join(parts, "__") + ".html"
 }
 
-func generateFilenameFromRelative(basePath, relativePath string) string {
-	dir := filepath.Dir(basePath)
-	absPath := filepath.Join(dir, relativePath)
-	return filepath.Base(absPath) + ".html"
+func RenderMarkdown(content string) string {
+	return markdownToHTML(content)
 }
 
 func markdownToHTML(content string) string {
@@ -368,6 +325,10 @@ func markdownToHTML(content string) string {
 			continue
 		}
 
+		if strings.HasPrefix(trimmed, "<!--") && strings.HasSuffix(trimmed, "-->") {
+			continue
+		}
+
 		if strings.HasPrefix(trimmed, "# ") {
 			result.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(strings.TrimPrefix(trimmed, "# "))))
 		} else if strings.HasPrefix(trimmed, "## ") {
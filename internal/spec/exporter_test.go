@@ -0,0 +1,75 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageFilename(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	tests := []struct {
+		path, want string
+	}{
+		{"/repo/internal/users/get.md", "internal__users__get.md.html"},
+		{"/repo/internal/orders/get.md", "internal__orders__get.md.html"},
+		{"/repo/api.md", "api.md.html"},
+		{"/other/api.md", "up__other__api.md.html"},
+	}
+	for _, tt := range tests {
+		if got := PageFilename(root, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("PageFilename(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExportSameBasenames(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"api.md":        "# API\n\n## Dependencies\n- [Users](users/get.md)\n- [Orders](orders/get.md)\n",
+		"users/get.md":  "# Получить пользователя\n",
+		"orders/get.md": "# Получить заказ\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	graph, err := BuildGraphFromRoots([]string{filepath.Join(root, "api.md")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(root, "build")
+	if _, err := ExportToHTML(graph, ExportOptions{Root: root, OutputDir: out}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, title := range map[string]string{
+		"users__get.md.html":  "Получить пользователя",
+		"orders__get.md.html": "Получить заказ",
+	} {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("страница %s не создана: %v", name, err)
+		}
+		if !strings.Contains(string(data), title) {
+			t.Errorf("страница %s не содержит «%s»", name, title)
+		}
+	}
+
+	api, err := os.ReadFile(filepath.Join(out, "api.md.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, href := range []string{`href="users__get.md.html"`, `href="orders__get.md.html"`} {
+		if !strings.Contains(string(api), href) {
+			t.Errorf("страница api.md не ссылается на %s", href)
+		}
+	}
+}
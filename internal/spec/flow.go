@@ -0,0 +1,36 @@
+package spec
+
+import (
+	"regexp"
+	"strings"
+)
+
+var flowRefRe = regexp.MustCompile(`(?:→|->)\s*(uses|reads|writes|calls|validates):\s*(?:\[[^\]]*\]\()?([^\s()#]+\.md)(?:#([^\s)]+))?\)?`)
+
+func parseFlow(lines []sourceLine) []FlowStep {
+	var steps []FlowStep
+
+	for _, item := range parseList(lines) {
+		step := FlowStep{Number: item.Number, Range: item.Range}
+		if step.Number == 0 {
+			step.Number = len(steps) + 1
+		}
+
+		var text []string
+		for i, line := range item.Lines {
+			raw := line.Text
+			if i == 0 {
+				raw = listItemRe.FindStringSubmatch(raw)[3]
+			}
+			if t := strings.TrimSpace(flowRefRe.ReplaceAllString(raw, "")); t != "" {
+				text = append(text, t)
+			}
+			step.Calls = append(step.Calls, line.Refs...)
+		}
+		step.Text = strings.Join(text, " ")
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
@@ -0,0 +1,132 @@
+package spec
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var DefaultHTTPLayers = []string{"controllers", "controller", "handlers", "handler", "transport", "http", "api"}
+
+var (
+	sqlRes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bselect\b.+\bfrom\b`),
+		regexp.MustCompile(`(?i)\binsert\s+into\b`),
+		regexp.MustCompile(`(?i)\bupdate\s+\S+\s+set\b`),
+		regexp.MustCompile(`(?i)\bdelete\s+from\b`),
+		regexp.MustCompile(`(?i)\b(?:create|alter|drop|truncate)\s+table\b`),
+		regexp.MustCompile(`\b(?:INNER |LEFT |RIGHT |OUTER )?JOIN\s+\S+\s+ON\b`),
+		regexp.MustCompile(`\bWHERE\s+\S+\s*(?:=|<|>|IN\b|LIKE\b|IS\b)`),
+		regexp.MustCompile(`\b(?:ORDER|GROUP)\s+BY\b`),
+	}
+	sqlFenceRe = regexp.MustCompile("^ {0,3}(?:`{3,}|~{3,})\\s*(?i:sql)\\b")
+
+	httpRes = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)(?:\s+/|[\s-]+(?:запрос|метод|эндпоинт|ручк|request|endpoint|method))`),
+		regexp.MustCompile(`(?i)(?:вернуть|возвращать|возвращает|возвращаем|вернёт|вернет|отдать|отдаёт|отдает|ответить|отвечает|return|returns|respond with|responds with)\s+(?:код\s+|статус\s+|ошибку\s+|code\s+|status\s+)?(?:200|201|202|204|301|302|304|400|401|403|404|405|409|410|422|429|500|502|503|504)\b`),
+		regexp.MustCompile(`(?i)\bhttp\s*[1-5]\d\d\b`),
+		regexp.MustCompile(`\b[1-5]\d\d\s+(?:OK|Created|Accepted|No Content|Moved Permanently|Found|Not Modified|Bad Request|Unauthorized|Forbidden|Not Found|Method Not Allowed|Conflict|Gone|Unprocessable Entity|Too Many Requests|Internal Server Error|Bad Gateway|Service Unavailable|Gateway Timeout)\b`),
+		regexp.MustCompile(`(?i)(?:статус|код ответа|код статуса|status code|status)\s*:?\s*[1-5]\d\d\b`),
+		regexp.MustCompile(`\bhttp\.Status\w+|\bStatus(?:OK|Created|BadRequest|Unauthorized|Forbidden|NotFound|Conflict|InternalServerError)\b`),
+		regexp.MustCompile(`(?i)\b(?:http[- ]?(?:handler|метод|запрос|ответ|статус)|хендлер|обработчик http)`),
+	}
+
+	lineRefRe = regexp.MustCompile(`[\w./-]+\.(?:go|ts|tsx|js|jsx|py|java|kt|rb|rs|cs|cpp|cc|c|h|php|swift|scala|sql|proto)(?::\d+(?::\d+)?|#L\d+(?:-L?\d+)?)`)
+)
+
+const fixSQL = "опишите, какие данные читаются и изменяются, без текста запроса"
+
+func checkForbiddenPractices(spec *Spec, opts LintOptions) []Diagnostic {
+	var diagnostics []Diagnostic
+	httpAllowed := inHTTPLayer(spec.Path, opts.Root, opts.HTTPLayers)
+
+	report := func(rule, severity string, line sourceLine, loc []int, message, fix string) {
+		diagnostics = append(diagnostics, Diagnostic{
+			RuleID:   rule,
+			Severity: severity,
+			File:     spec.Path,
+			Line:     line.Number,
+			Column:   column(line.Text, loc[0]),
+			End:      Position{Line: line.Number, Column: column(line.Text, loc[1])},
+			Message:  message,
+			Fix:      fix,
+		})
+	}
+
+	block, sqlBlock := 0, 0
+	for _, line := range scanLines(spec.Content) {
+		opening := line.Fenced && line.Block != block
+		block = line.Block
+		if line.Fenced && line.Block == sqlBlock {
+			continue
+		}
+
+		if opening && sqlFenceRe.MatchString(line.Text) {
+			sqlBlock = line.Block
+			report("sql", SeverityWarning, line, []int{0, len(line.Text)}, "спецификация описывает SQL-запрос (блок кода sql)", fixSQL)
+			continue
+		}
+		if loc := firstMatch(sqlRes, line.Text); loc != nil {
+			report("sql", SeverityWarning, line, loc, fmt.Sprintf("спецификация описывает SQL-запрос: «%s»", truncateLabel(line.Text[loc[0]:loc[1]], 40)), fixSQL)
+		}
+
+		if line.Fenced {
+			continue
+		}
+
+		if !httpAllowed {
+			if loc := firstMatch(httpRes, line.Text); loc != nil {
+				report("http-outside-controller", SeverityWarning, line, loc, fmt.Sprintf("детали HTTP вне слоя контроллеров: «%s»", line.Text[loc[0]:loc[1]]),
+					"опишите результат в терминах предметной области, детали HTTP оставьте спецификации контроллера")
+			}
+		}
+
+		for _, loc := range lineRefRe.FindAllStringIndex(line.Text, -1) {
+			report("line-reference", SeverityError, line, loc, fmt.Sprintf("ссылка на номер строки кода: %s", line.Text[loc[0]:loc[1]]),
+				"ссылайтесь на спецификацию, тип или функцию вместо номера строки")
+		}
+	}
+
+	return diagnostics
+}
+
+func inHTTPLayer(specPath, root string, layers []string) bool {
+	if layers == nil {
+		layers = DefaultHTTPLayers
+	}
+
+	rel := RelPath(specPath)
+	if root != "" {
+		if r, err := filepath.Rel(root, specPath); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+	}
+
+	parts := strings.Split(path.Dir(rel), "/")
+	name := strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+	parts = append(parts, strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})...)
+
+	for _, part := range parts {
+		for _, layer := range layers {
+			if strings.EqualFold(part, layer) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func firstMatch(res []*regexp.Regexp, text string) []int {
+	var first []int
+	for _, re := range res {
+		if loc := re.FindStringIndex(text); loc != nil && (first == nil || loc[0] < first[0]) {
+			first = loc
+		}
+	}
+	return first
+}
@@ -0,0 +1,67 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInHTTPLayer(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/controllers/user.md", true},
+		{"internal/http/user.md", true},
+		{"internal/services/user.md", false},
+		{"examples/controller_spec.md", true},
+		{"internal/app/create_user_handler.md", true},
+		{"internal/app/user-controller.md", true},
+		{"internal/usecases/create_user.md", false},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(root, filepath.FromSlash(tt.path))
+		if got := inHTTPLayer(path, root, nil); got != tt.want {
+			t.Errorf("inHTTPLayer(%s) = %v, ожидалось %v", tt.path, got, tt.want)
+		}
+	}
+
+	if inHTTPLayer(filepath.Join(root, "controllers", "user.md"), root, []string{"handlers"}) {
+		t.Error("при заданных lint.http_layers каталоги по умолчанию не должны учитываться")
+	}
+}
+
+func TestShippedExamplesLintClean(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "fs", "assets", "examples", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("не найдены примеры спецификаций")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", "fs", "assets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Examples link to neighbouring specs of a real project that are not shipped.
+		opts := LintOptions{Root: root, Disabled: []string{"broken-link"}}
+		for _, d := range LintSpec(ParseContent(abs, string(data)), opts) {
+			t.Errorf("%s:%d: [%s] %s", filepath.Base(path), d.Line, d.RuleID, d.Message)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package spec
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var FormatOrder = []SectionKind{
+	SectionResponsibility,
+	SectionInputs,
+	SectionOutputs,
+	SectionBusinessRules,
+	SectionFlow,
+	SectionDependencies,
+	SectionErrors,
+	SectionNotes,
+}
+
+type formatSection struct {
+	Section
+	lines []sourceLine
+}
+
+func Format(spec *Spec, names *SectionNames) string {
+	lines := scanLines(strings.TrimRight(spec.Content, "\r\n"))
+
+	preambleEnd := len(lines)
+	if len(spec.Sections) > 0 {
+		preambleEnd = spec.Sections[0].Heading.Start.Line - 1
+	}
+
+	sections := make([]formatSection, len(spec.Sections))
+	for i, section := range spec.Sections {
+		end := len(lines)
+		if i+1 < len(spec.Sections) {
+			end = spec.Sections[i+1].Heading.Start.Line - 1
+		}
+		sections[i] = formatSection{Section: section, lines: lines[section.Heading.Start.Line:end]}
+	}
+
+	var out []string
+	out = append(out, formatPreamble(lines[:preambleEnd])...)
+
+	write := func(heading string, body []string) {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, "## "+heading)
+		out = append(out, body...)
+	}
+
+	stubs := canonicalSectionNames[sectionLanguage(spec.Sections, sectionNamesOrDefault(names).Language)]
+
+	for _, kind := range FormatOrder {
+		found := false
+		for _, section := range sections {
+			if section.Kind != kind {
+				continue
+			}
+			found = true
+			write(section.Name, formatSectionBody(spec, section))
+		}
+
+		if !found && kind != SectionNotes {
+			if kind == SectionDependencies {
+				write(stubs[kind], formatDependencies(spec, nil))
+			} else {
+				write(stubs[kind], nil)
+			}
+		}
+	}
+
+	for _, section := range sections {
+		if section.Kind == SectionUnknown {
+			write(section.Name, formatSectionBody(spec, section))
+		}
+	}
+
+	return strings.Join(out, "\n") + "\n"
+}
+
+func formatPreamble(lines []sourceLine) []string {
+	var out []string
+	for _, line := range lines {
+		text := line.Text
+		if !line.Fenced {
+			if heading, ok := parseHeading(line); ok && heading.Level == 1 {
+				text = "# " + heading.Text
+			}
+		}
+		out = append(out, text)
+	}
+	return trimBlankLines(out)
+}
+
+func formatSectionBody(spec *Spec, section formatSection) []string {
+	switch section.Kind {
+	case SectionBusinessRules, SectionFlow:
+		return trimBlankLines(renumberList(section.lines))
+	case SectionDependencies:
+		return formatDependencies(spec, section.lines)
+	}
+	return trimBlankLines(normalizedLines(section.lines))
+}
+
+func normalizedLines(lines []sourceLine) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.Fenced {
+			out = append(out, line.Text)
+			continue
+		}
+		out = append(out, normalizeRefs(line.Text))
+	}
+	return out
+}
+
+func normalizeRefs(text string) string {
+	masked := maskCodeSpans(text)
+	locs := flowRefRe.FindAllStringSubmatchIndex(masked, -1)
+
+	for i := len(locs) - 1; i >= 0; i-- {
+		loc := locs[i]
+		ref := fmt.Sprintf("→ %s: %s", text[loc[2]:loc[3]], text[loc[4]:loc[5]])
+		if loc[6] >= 0 {
+			ref += "#" + text[loc[6]:loc[7]]
+		}
+		text = text[:loc[0]] + ref + text[loc[1]:]
+	}
+
+	return text
+}
+
+func renumberList(lines []sourceLine) []string {
+	out := normalizedLines(lines)
+	indent := -1
+	number := 0
+
+	for i, line := range lines {
+		if line.Fenced {
+			continue
+		}
+
+		m := listItemRe.FindStringSubmatchIndex(out[i])
+		if m == nil {
+			continue
+		}
+
+		width := m[3] - m[2]
+		if indent < 0 {
+			indent = width
+		}
+		if width != indent {
+			continue
+		}
+
+		number++
+		out[i] = fmt.Sprintf("%s%d. %s", out[i][:width], number, out[i][m[6]:m[7]])
+	}
+
+	return out
+}
+
+func formatDependencies(spec *Spec, lines []sourceLine) []string {
+	items := parseList(lines)
+
+	var intro, unlinked []string
+	for _, line := range lines {
+		if len(items) > 0 && line.Number >= items[0].Lines[0].Number {
+			break
+		}
+		if !line.Fenced {
+			intro = append(intro, normalizeRefs(line.Text))
+		}
+	}
+
+	type dependency struct {
+		key   string
+		lines []string
+	}
+	var linked []dependency
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		text := make([]string, 0, len(item.Lines))
+		for _, line := range item.Lines {
+			text = append(text, normalizeRefs(line.Text))
+		}
+
+		m := linkRe.FindStringSubmatch(maskCodeSpans(item.Text))
+		if m == nil {
+			unlinked = append(unlinked, text...)
+			continue
+		}
+
+		key := dependencyKey(m[2])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		linked = append(linked, dependency{key: key, lines: text})
+	}
+
+	for _, line := range lines {
+		if line.Fenced {
+			unlinked = append(unlinked, line.Text)
+		}
+	}
+
+	for _, step := range spec.Flow {
+		for _, call := range step.Calls {
+			key := dependencyKey(call.Path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			title := dependencyTitle(spec.Path, call.Path)
+			linked = append(linked, dependency{key: key, lines: []string{fmt.Sprintf("- [%s](%s)", title, call.Path)}})
+		}
+	}
+
+	sort.SliceStable(linked, func(i, j int) bool {
+		return linked[i].key < linked[j].key
+	})
+
+	out := trimBlankLines(intro)
+	if len(out) > 0 && (len(linked) > 0 || len(unlinked) > 0) {
+		out = append(out, "")
+	}
+	for _, dep := range linked {
+		out = append(out, dep.lines...)
+	}
+	out = append(out, unlinked...)
+
+	return out
+}
+
+func dependencyTitle(specPath, target string) string {
+	if s, err := ParseFile(filepath.Join(filepath.Dir(specPath), target)); err == nil && s.Title != "" {
+		return s.Title
+	}
+	return strings.TrimSuffix(path.Base(filepath.ToSlash(target)), ".md")
+}
+
+func trimBlankLines(lines []string) []string {
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[start:end]
+}
@@ -0,0 +1,62 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatStubsFollowHeadingLanguage(t *testing.T) {
+	english, err := NewSectionNames(LanguageEnglish, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	russian, err := NewSectionNames(LanguageRussian, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		names   *SectionNames
+		want    []string
+		absent  []string
+	}{
+		{
+			name:    "русская спека при английском конфиге",
+			content: "# Сервис\n\n## Ответственность\nДелает работу.\n\n## Поток\n1. Шаг\n",
+			names:   english,
+			want:    []string{"## Ответственность", "## Входные данные", "## Выходные данные", "## Бизнес-правила", "## Поток", "## Зависимости", "## Ошибки"},
+			absent:  []string{"## Inputs", "## Dependencies"},
+		},
+		{
+			name:    "английская спека при русском конфиге",
+			content: "# Service\n\n## Responsibility\nDoes work.\n",
+			names:   russian,
+			want:    []string{"## Responsibility", "## Inputs", "## Errors"},
+			absent:  []string{"## Входные данные", "## Ошибки"},
+		},
+		{
+			name:    "без секций используется язык конфига",
+			content: "# Сервис\n",
+			names:   russian,
+			want:    []string{"## Ответственность", "## Ошибки"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(ParseContentWith("/specs/x.md", tt.content, tt.names), tt.names)
+			for _, heading := range tt.want {
+				if !strings.Contains(got, heading+"\n") {
+					t.Errorf("нет заголовка %q:\n%s", heading, got)
+				}
+			}
+			for _, heading := range tt.absent {
+				if strings.Contains(got, heading+"\n") {
+					t.Errorf("лишний заголовок %q:\n%s", heading, got)
+				}
+			}
+		})
+	}
+}
@@ -5,10 +5,16 @@ import (
 )
 
 func CollectAllReferences(specFiles []string) map[string]bool {
+	return NewCache().CollectAllReferences(specFiles)
+}
+
+func (c *Cache) CollectAllReferences(specFiles []string) map[string]bool {
 	referenced := map[string]bool{}
 
+	c.Preload(specFiles, 0)
+
 	for _, f := range specFiles {
-		_, edges, err := ParseDependencies(f)
+		_, edges, err := c.ParseDependencies(f)
 		if err != nil {
 			continue
 		}
@@ -35,6 +41,10 @@ func FindRootSpecs(specs []string, referenced map[string]bool) []string {
 }
 
 func BuildGraphFromRoots(rootSpecs []string) (*Graph, error) {
+	return NewCache().BuildGraphFromRoots(rootSpecs)
+}
+
+func (c *Cache) BuildGraphFromRoots(rootSpecs []string) (*Graph, error) {
 	graph := &Graph{
 		Nodes: map[string]*Node{},
 		Edges: []Edge{},
@@ -43,7 +53,7 @@ func BuildGraphFromRoots(rootSpecs []string) (*Graph, error) {
 	visited := map[string]bool{}
 
 	for _, root := range rootSpecs {
-		if err := walkSpec(root, graph, visited); err != nil {
+		if err := c.walkSpec(root, graph, visited); err != nil {
 			return nil, err
 		}
 	}
@@ -51,7 +61,7 @@ func BuildGraphFromRoots(rootSpecs []string) (*Graph, error) {
 	return graph, nil
 }
 
-func walkSpec(specPath string, graph *Graph, visited map[string]bool) error {
+func (c *Cache) walkSpec(specPath string, graph *Graph, visited map[string]bool) error {
 	if visited[specPath] {
 		return nil
 	}
@@ -65,15 +75,17 @@ func walkSpec(specPath string, graph *Graph, visited map[string]bool) error {
 		}
 	}
 
-	_, edges, err := ParseDependencies(specPath)
+	_, edges, err := c.ParseDependencies(specPath)
 	if err != nil {
 		return nil
 	}
 
 	for _, edge := range edges {
 		graph.Edges = append(graph.Edges, Edge{
-			From: specPath,
-			To:   edge.To,
+			From:  specPath,
+			To:    edge.To,
+			Kind:  edge.Kind,
+			Kinds: edge.Kinds,
 		})
 
 		if _, exists := graph.Nodes[edge.To]; !exists {
@@ -84,7 +96,7 @@ func walkSpec(specPath string, graph *Graph, visited map[string]bool) error {
 			}
 		}
 
-		if err := walkSpec(edge.To, graph, visited); err != nil {
+		if err := c.walkSpec(edge.To, graph, visited); err != nil {
 			return err
 		}
 	}
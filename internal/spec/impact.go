@@ -0,0 +1,75 @@
+package spec
+
+import (
+	"sort"
+)
+
+type ImpactEntry struct {
+	Path  string
+	Depth int
+}
+
+func Dependents(graph *Graph, specPath string) []string {
+	seen := map[string]bool{}
+	var result []string
+
+	for _, edge := range graph.Edges {
+		if edge.To == specPath && edge.From != specPath && !seen[edge.From] {
+			seen[edge.From] = true
+			result = append(result, edge.From)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func Dependencies(graph *Graph, specPath string) []string {
+	seen := map[string]bool{}
+	var result []string
+
+	for _, edge := range graph.Edges {
+		if edge.From == specPath && edge.To != specPath && !seen[edge.To] {
+			seen[edge.To] = true
+			result = append(result, edge.To)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func Impact(graph *Graph, specPath string) []ImpactEntry {
+	depths := map[string]int{specPath: 0}
+	queue := []string{specPath}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range Dependents(graph, current) {
+			if _, seen := depths[dependent]; seen {
+				continue
+			}
+			depths[dependent] = depths[current] + 1
+			queue = append(queue, dependent)
+		}
+	}
+
+	var result []ImpactEntry
+	for path, depth := range depths {
+		if path == specPath {
+			continue
+		}
+		result = append(result, ImpactEntry{Path: path, Depth: depth})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Depth != result[j].Depth {
+			return result[i].Depth < result[j].Depth
+		}
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
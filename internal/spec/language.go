@@ -0,0 +1,183 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	LanguageOff  = "off"
+	minRatioSize = 20
+)
+
+type LanguageOptions struct {
+	Require  string
+	MinRatio float64
+	MinWords int
+}
+
+var (
+	urlRe      = regexp.MustCompile(`https?://\S+`)
+	sentenceRe = regexp.MustCompile(`[^.!?;]+[.!?;]*`)
+	wordRe     = regexp.MustCompile(`[\p{L}\d_](?:[\p{L}\d_./\-]*[\p{L}\d_])?`)
+)
+
+func (o LanguageOptions) withDefaults() LanguageOptions {
+	if o.Require == "" {
+		o.Require = LanguageRussian
+	}
+	if o.MinRatio == 0 {
+		o.MinRatio = 0.6
+	}
+	if o.MinWords == 0 {
+		o.MinWords = 3
+	}
+	return o
+}
+
+func checkLanguage(spec *Spec, opts LanguageOptions) []Diagnostic {
+	opts = opts.withDefaults()
+	if opts.Require == LanguageOff {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	lines := scanLines(spec.Content)
+
+	for _, section := range spec.Sections {
+		var required, foreign int
+
+		for _, line := range lines[section.Heading.Start.Line:section.Range.End.Line] {
+			if line.Fenced {
+				continue
+			}
+			if _, ok := parseHeading(line); ok {
+				continue
+			}
+
+			prose := stripNonProse(line.Text)
+			for _, loc := range sentenceRe.FindAllStringIndex(prose, -1) {
+				req, frn := countWords(prose[loc[0]:loc[1]], opts.Require)
+				if req == 0 && frn >= opts.MinWords {
+					original := line.Text[loc[0]:loc[1]]
+					start := loc[0] + len(original) - len(strings.TrimLeftFunc(original, unicode.IsSpace))
+					diagnostics = append(diagnostics, Diagnostic{
+						RuleID:   "language",
+						Severity: SeverityWarning,
+						File:     spec.Path,
+						Line:     line.Number,
+						Column:   column(line.Text, start),
+						End:      Position{Line: line.Number, Column: column(line.Text, loc[1])},
+						Message:  fmt.Sprintf("текст не на %s: «%s»", languageName(opts.Require), truncateLabel(strings.Join(strings.Fields(original), " "), 60)),
+						Fix:      fmt.Sprintf("перепишите предложение на %s", languageName(opts.Require)),
+					})
+				}
+			}
+
+			req, frn := countLetters(prose, opts.Require)
+			required += req
+			foreign += frn
+		}
+
+		total := required + foreign
+		if total < minRatioSize {
+			continue
+		}
+		if ratio := float64(required) / float64(total); ratio < opts.MinRatio {
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "language",
+				Severity: SeverityWarning,
+				File:     spec.Path,
+				Line:     section.Heading.Start.Line,
+				Column:   section.Heading.Start.Column,
+				End:      section.Heading.End,
+				Message: fmt.Sprintf("секция «%s»: доля текста на %s %.0f%% ниже порога %.0f%%",
+					section.Name, languageName(opts.Require), ratio*100, opts.MinRatio*100),
+				Fix: fmt.Sprintf("перепишите секцию на %s", languageName(opts.Require)),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+func stripNonProse(text string) string {
+	text = maskCodeSpans(text)
+	text = maskMatches(text, flowRefRe)
+	text = maskMatches(text, linkRe)
+	text = maskMatches(text, urlRe)
+
+	return wordRe.ReplaceAllStringFunc(text, func(word string) string {
+		if isIdentifier(word) {
+			return strings.Repeat(" ", len(word))
+		}
+		return word
+	})
+}
+
+func maskMatches(text string, re *regexp.Regexp) string {
+	return re.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Repeat(" ", len(match))
+	})
+}
+
+func isIdentifier(word string) bool {
+	if strings.ContainsAny(word, "_./-0123456789") {
+		return true
+	}
+
+	runes := []rune(word)
+	upper := 0
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+			if i > 0 && unicode.IsLower(runes[i-1]) {
+				return true
+			}
+		}
+	}
+
+	return len(runes) > 1 && upper == len(runes)
+}
+
+func countWords(text, language string) (required, foreign int) {
+	for _, word := range wordRe.FindAllString(text, -1) {
+		req, frn := countLetters(word, language)
+		switch {
+		case req > 0:
+			required++
+		case frn > 0:
+			foreign++
+		}
+	}
+	return required, foreign
+}
+
+func countLetters(text, language string) (required, foreign int) {
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			if language == LanguageRussian {
+				required++
+			} else {
+				foreign++
+			}
+		case unicode.Is(unicode.Latin, r):
+			if language == LanguageRussian {
+				foreign++
+			} else {
+				required++
+			}
+		}
+	}
+	return required, foreign
+}
+
+func languageName(language string) string {
+	if language == LanguageEnglish {
+		return "английском"
+	}
+	return "русском"
+}
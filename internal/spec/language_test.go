@@ -0,0 +1,32 @@
+package spec
+
+import "testing"
+
+func TestCheckLanguageColumns(t *testing.T) {
+	tests := []struct {
+		line        string
+		column, end int
+	}{
+		{"Сервис выполняет запрос к базе данных `users`. SELECT id FROM users WHERE active is true", 48, 89},
+		{"Сервис выполняет [запрос](db.md). SELECT id FROM users WHERE active is true", 35, 76},
+		{"Сервис выполняет запрос UserRepo. The handler returns all active users", 35, 71},
+		{"The handler returns all active users", 1, 37},
+	}
+
+	for _, tt := range tests {
+		s := ParseContent("/specs/x.md", "# X\n\n## Responsibility\n"+tt.line+"\n")
+		var found []Diagnostic
+		for _, d := range checkLanguage(s, LanguageOptions{}) {
+			if d.Line == 4 {
+				found = append(found, d)
+			}
+		}
+		if len(found) != 1 {
+			t.Errorf("%q: ожидалась одна находка на строке 4, получено %+v", tt.line, found)
+			continue
+		}
+		if d := found[0]; d.Column != tt.column || d.End.Column != tt.end {
+			t.Errorf("%q: колонки %d–%d, ожидались %d–%d", tt.line, d.Column, d.End.Column, tt.column, tt.end)
+		}
+	}
+}
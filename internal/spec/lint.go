@@ -0,0 +1,226 @@
+package spec
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var MandatorySections = []SectionKind{
+	SectionResponsibility,
+	SectionInputs,
+	SectionOutputs,
+	SectionBusinessRules,
+	SectionFlow,
+	SectionDependencies,
+	SectionErrors,
+}
+
+type Diagnostic struct {
+	RuleID   string
+	Severity string
+	File     string
+	Line     int
+	Column   int
+	End      Position
+	Message  string
+	Fix      string
+}
+
+func (d Diagnostic) Range() Range {
+	start := Position{Line: max(d.Line, 1), Column: max(d.Column, 1)}
+	if positionLess(d.End, start) {
+		return Range{Start: start, End: start}
+	}
+	return Range{Start: start, End: d.End}
+}
+
+var LintRules = []string{
+	"missing-section",
+	"broken-link",
+	"language",
+	"sql",
+	"http-outside-controller",
+	"line-reference",
+	"undeclared-dependency",
+	"unused-dependency",
+}
+
+var disableDirectiveRe = regexp.MustCompile(`<!--\s*spec-agent-disable\b([^>]*?)\s*-->`)
+
+type LintOptions struct {
+	Root       string
+	Sections   *SectionNames
+	Language   LanguageOptions
+	Disabled   []string
+	HTTPLayers []string
+}
+
+func Lint(graph *Graph, cache *Cache, opts LintOptions) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, path := range sortedNodePaths(graph) {
+		spec, err := cache.ParseFile(path)
+		if err != nil {
+			continue
+		}
+		diagnostics = append(diagnostics, LintSpec(spec, opts)...)
+	}
+
+	sortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+func LintSpec(spec *Spec, opts LintOptions) []Diagnostic {
+	var diagnostics []Diagnostic
+	diagnostics = append(diagnostics, checkMandatorySections(spec, opts.Sections)...)
+	diagnostics = append(diagnostics, checkBrokenLinks(spec)...)
+	diagnostics = append(diagnostics, checkLanguage(spec, opts.Language)...)
+	diagnostics = append(diagnostics, checkForbiddenPractices(spec, opts)...)
+	diagnostics = append(diagnostics, checkFlowDependencies(spec)...)
+	return filterDisabled(spec, diagnostics, opts.Disabled)
+}
+
+func checkMandatorySections(spec *Spec, names *SectionNames) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, kind := range MandatorySections {
+		if spec.HasSection(kind) {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			RuleID:   "missing-section",
+			Severity: SeverityWarning,
+			File:     spec.Path,
+			Line:     1,
+			Message:  fmt.Sprintf("отсутствует обязательная секция «## %s»", names.Name(kind)),
+			Fix:      fmt.Sprintf("добавьте секцию «## %s»", names.Name(kind)),
+		})
+	}
+
+	return diagnostics
+}
+
+func checkBrokenLinks(spec *Spec) []Diagnostic {
+	var diagnostics []Diagnostic
+	seen := map[string]bool{}
+	dir := filepath.Dir(spec.Path)
+
+	type target struct {
+		path string
+		r    Range
+	}
+	targets := make([]target, 0, len(spec.Links)+len(spec.Refs))
+	for _, link := range spec.Links {
+		targets = append(targets, target{link.Path, link.Range})
+	}
+	for _, ref := range spec.Refs {
+		targets = append(targets, target{ref.Path, ref.Range})
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return positionLess(targets[i].r.Start, targets[j].r.Start)
+	})
+
+	for _, t := range targets {
+		if seen[t.path] {
+			continue
+		}
+		seen[t.path] = true
+
+		if fileExists(filepath.Join(dir, t.path)) {
+			continue
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			RuleID:   "broken-link",
+			Severity: SeverityError,
+			File:     spec.Path,
+			Line:     t.r.Start.Line,
+			Column:   t.r.Start.Column,
+			End:      t.r.End,
+			Message:  fmt.Sprintf("ссылка на несуществующую спецификацию %s", t.path),
+			Fix:      "исправьте путь или создайте спецификацию",
+		})
+	}
+
+	return diagnostics
+}
+
+func filterDisabled(spec *Spec, diagnostics []Diagnostic, disabled []string) []Diagnostic {
+	off := map[string]bool{}
+	for _, rule := range disabled {
+		off[rule] = true
+	}
+
+	all := false
+	for _, m := range disableDirectiveRe.FindAllStringSubmatch(spec.Content, -1) {
+		rules := strings.FieldsFunc(m[1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(rules) == 0 {
+			all = true
+		}
+		for _, rule := range rules {
+			off[rule] = true
+		}
+	}
+
+	if all {
+		return nil
+	}
+	if len(off) == 0 {
+		return diagnostics
+	}
+
+	var result []Diagnostic
+	for _, d := range diagnostics {
+		if !off[d.RuleID] {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func positionLess(a, b Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+func sortedNodePaths(graph *Graph) []string {
+	paths := make([]string, 0, len(graph.Nodes))
+	for path := range graph.Nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File != diagnostics[j].File {
+			return diagnostics[i].File < diagnostics[j].File
+		}
+		return positionLess(
+			Position{Line: diagnostics[i].Line, Column: diagnostics[i].Column},
+			Position{Line: diagnostics[j].Line, Column: diagnostics[j].Column},
+		)
+	})
+}
@@ -0,0 +1,112 @@
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const manifestFile = "manifest.json"
+
+type ExportResult struct {
+	Written   []string
+	Unchanged []string
+	Removed   []string
+}
+
+type buildManifest struct {
+	Files map[string]string `json:"files"`
+}
+
+type buildWriter struct {
+	outputDir string
+	force     bool
+	previous  buildManifest
+
+	mu      sync.Mutex
+	current buildManifest
+	result  ExportResult
+}
+
+func newBuildWriter(outputDir string, force bool) *buildWriter {
+	w := &buildWriter{
+		outputDir: outputDir,
+		force:     force,
+		previous:  buildManifest{Files: map[string]string{}},
+		current:   buildManifest{Files: map[string]string{}},
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, manifestFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &w.previous)
+		if w.previous.Files == nil {
+			w.previous.Files = map[string]string{}
+		}
+	}
+
+	return w
+}
+
+func (w *buildWriter) Write(name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	dest := filepath.Join(w.outputDir, name)
+
+	unchanged := !w.force && w.previous.Files[name] == hash && fileExists(dest)
+	if !unchanged {
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return fmt.Errorf("не удалось записать %s: %w", name, err)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.current.Files[name] = hash
+	if unchanged {
+		w.result.Unchanged = append(w.result.Unchanged, name)
+	} else {
+		w.result.Written = append(w.result.Written, name)
+	}
+
+	return nil
+}
+
+func (w *buildWriter) Finish() (*ExportResult, error) {
+	for name := range w.previous.Files {
+		if _, ok := w.current.Files[name]; ok {
+			continue
+		}
+		err := os.Remove(filepath.Join(w.outputDir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("не удалось удалить устаревший файл %s: %w", name, err)
+		}
+		w.result.Removed = append(w.result.Removed, name)
+	}
+
+	data, err := json.MarshalIndent(w.current, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(w.outputDir, manifestFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("не удалось записать %s: %w", manifestFile, err)
+	}
+
+	sort.Strings(w.result.Written)
+	sort.Strings(w.result.Unchanged)
+	sort.Strings(w.result.Removed)
+
+	return &w.result, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
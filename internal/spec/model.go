@@ -1,16 +1,88 @@
 package spec
 
 type Spec struct {
-	Path     string
-	Title    string
-	Sections map[string]string
-	Content  string
-	Links    []SpecLink
+	Path          string
+	Title         string
+	TitleRange    Range
+	Headings      []Heading
+	Sections      []Section
+	Content       string
+	Links         []SpecLink
+	Refs          []SpecRef
+	Inputs        []Item
+	Outputs       []Item
+	BusinessRules []BusinessRule
+	Flow          []FlowStep
+	Dependencies  []Dependency
+	Errors        []ErrorDef
+}
+
+type Position struct {
+	Line   int
+	Column int
+}
+
+type Range struct {
+	Start Position
+	End   Position
+}
+
+type Heading struct {
+	Level int
+	Text  string
+	Range Range
+}
+
+type Section struct {
+	Name    string
+	Kind    SectionKind
+	Heading Range
+	Range   Range
+	Body    string
 }
 
 type SpecLink struct {
 	Title string
 	Path  string
+	Range Range
+}
+
+type SpecRef struct {
+	Kind   string
+	Path   string
+	Anchor string
+	Range  Range
+}
+
+type Item struct {
+	Text  string
+	Range Range
+}
+
+type BusinessRule struct {
+	Number int
+	Text   string
+	Range  Range
+}
+
+type FlowStep struct {
+	Number int
+	Text   string
+	Calls  []SpecRef
+	Range  Range
+}
+
+type Dependency struct {
+	Title string
+	Path  string
+	Text  string
+	Range Range
+}
+
+type ErrorDef struct {
+	Name        string
+	Description string
+	Range       Range
 }
 
 type Graph struct {
@@ -25,11 +97,26 @@ type Node struct {
 }
 
 type Edge struct {
-	From string
-	To   string
+	From  string
+	To    string
+	Kind  string
+	Kinds []string
 }
 
 type ExportTree struct {
 	Specs    map[string]*Spec
 	RootSpecs []string
 }
+
+func (s *Spec) Section(kind SectionKind) *Section {
+	for i := range s.Sections {
+		if s.Sections[i].Kind == kind {
+			return &s.Sections[i]
+		}
+	}
+	return nil
+}
+
+func (s *Spec) HasSection(kind SectionKind) bool {
+	return s.Section(kind) != nil
+}
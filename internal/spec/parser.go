@@ -4,51 +4,351 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+var (
+	headingRe     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	closingHashRe = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	fenceRe       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	linkRe        = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+\.md)\)`)
+	listItemRe    = regexp.MustCompile(`^(\s*)(?:[-*+]|(\d+)[.)])\s+(.*)$`)
+	errorDefRe    = regexp.MustCompile(`^(.+?)\s+[—–-]+\s+(.*)$`)
+	errorColonRe  = regexp.MustCompile("^(`?[\\p{L}\\d_.]+`?):\\s+(.*)$")
+)
+
+type sourceLine struct {
+	Number int
+	Text   string
+	Fenced bool
+	Block  int
+	Refs   []SpecRef
+}
+
+type listItem struct {
+	Number int
+	Text   string
+	Lines  []sourceLine
+	Range  Range
+}
+
 func ParseFile(path string) (*Spec, error) {
+	return ParseFileWith(path, nil)
+}
+
+func ParseFileWith(path string, names *SectionNames) (*Spec, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	content := string(data)
-	lines := strings.Split(content, "\n")
+	return ParseContentWith(path, string(data), names), nil
+}
+
+func ParseContent(path, content string) *Spec {
+	return ParseContentWith(path, content, nil)
+}
 
+func ParseContentWith(path, content string, names *SectionNames) *Spec {
 	spec := &Spec{
-		Path:     path,
-		Content:  content,
-		Sections: map[string]string{},
-		Links:    []SpecLink{},
+		Path:    path,
+		Content: content,
+		Links:   []SpecLink{},
 	}
 
-	var current string
-	re := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+\.md)\)`)
+	lines := scanLines(content)
+
+	var current *Section
+	var body []sourceLine
+	closeSection := func() {
+		if current == nil {
+			return
+		}
+		current.Body, current.Range.End = sectionBody(body, current.Heading.End)
+		spec.Sections = append(spec.Sections, *current)
+		spec.fillSection(current, body)
+		current, body = nil, nil
+	}
 
 	for _, line := range lines {
-		if strings.HasPrefix(line, "## ") {
-			current = strings.TrimPrefix(line, "## ")
-			spec.Sections[current] = ""
+		if !line.Fenced {
+			if heading, ok := parseHeading(line); ok {
+				spec.Headings = append(spec.Headings, heading)
+
+				switch {
+				case heading.Level == 1 && spec.Title == "":
+					spec.Title = heading.Text
+					spec.TitleRange = heading.Range
+					continue
+				case heading.Level == 2:
+					closeSection()
+					current = &Section{
+						Name:    heading.Text,
+						Kind:    names.Kind(heading.Text),
+						Heading: heading.Range,
+						Range:   Range{Start: heading.Range.Start},
+					}
+					continue
+				}
+			}
+
+			masked := maskCodeSpans(line.Text)
+			for _, loc := range linkRe.FindAllStringSubmatchIndex(masked, -1) {
+				spec.Links = append(spec.Links, SpecLink{
+					Title: line.Text[loc[2]:loc[3]],
+					Path:  line.Text[loc[4]:loc[5]],
+					Range: lineRange(line, loc[0], loc[1]),
+				})
+			}
+			spec.Refs = append(spec.Refs, line.Refs...)
+		}
+
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	closeSection()
+
+	return spec
+}
+
+func scanLines(content string) []sourceLine {
+	raw := strings.Split(content, "\n")
+	lines := make([]sourceLine, 0, len(raw))
+
+	var fence string
+	var blocks int
+	for i, text := range raw {
+		line := sourceLine{Number: i + 1, Text: strings.TrimSuffix(text, "\r")}
+
+		if m := fenceRe.FindStringSubmatch(line.Text); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+				blocks++
+				line.Fenced = true
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(line.Text) == m[1]:
+				fence = ""
+				line.Fenced = true
+			}
+		}
+		if fence != "" {
+			line.Fenced = true
+		}
+		if line.Fenced {
+			line.Block = blocks
+		}
+
+		if !line.Fenced {
+			line.Refs = lineRefs(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func parseHeading(line sourceLine) (Heading, bool) {
+	m := headingRe.FindStringSubmatchIndex(line.Text)
+	if m == nil {
+		return Heading{}, false
+	}
+
+	text := ""
+	if m[4] >= 0 {
+		text = closingHashRe.ReplaceAllString(line.Text[m[4]:m[5]], "")
+	}
+
+	return Heading{
+		Level: m[3] - m[2],
+		Text:  strings.TrimSpace(text),
+		Range: lineRange(line, m[2], len(line.Text)),
+	}, true
+}
+
+func lineRefs(line sourceLine) []SpecRef {
+	var refs []SpecRef
+	masked := maskCodeSpans(line.Text)
+
+	for _, loc := range flowRefRe.FindAllStringSubmatchIndex(masked, -1) {
+		ref := SpecRef{
+			Kind:  line.Text[loc[2]:loc[3]],
+			Path:  line.Text[loc[4]:loc[5]],
+			Range: lineRange(line, loc[0], loc[1]),
+		}
+		if loc[6] >= 0 {
+			ref.Anchor = line.Text[loc[6]:loc[7]]
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+func maskCodeSpans(text string) string {
+	if !strings.Contains(text, "`") {
+		return text
+	}
+
+	masked := []byte(text)
+	for i := 0; i < len(masked); {
+		if masked[i] != '`' {
+			i++
+			continue
+		}
+
+		n := 0
+		for i+n < len(masked) && masked[i+n] == '`' {
+			n++
+		}
+		delim := strings.Repeat("`", n)
+		end := strings.Index(text[i+n:], delim)
+		if end < 0 {
+			i += n
 			continue
 		}
-		if strings.HasPrefix(line, "# ") && spec.Title == "" {
-			spec.Title = strings.TrimPrefix(line, "# ")
+
+		stop := i + n + end + n
+		for j := i; j < stop; j++ {
+			masked[j] = ' '
+		}
+		i = stop
+	}
+
+	return string(masked)
+}
+
+func lineRange(line sourceLine, start, end int) Range {
+	return Range{
+		Start: Position{Line: line.Number, Column: column(line.Text, start)},
+		End:   Position{Line: line.Number, Column: column(line.Text, end)},
+	}
+}
+
+func column(text string, offset int) int {
+	return utf8.RuneCountInString(text[:offset]) + 1
+}
+
+func endOf(line sourceLine) Position {
+	return Position{Line: line.Number, Column: column(line.Text, len(line.Text))}
+}
+
+func sectionBody(lines []sourceLine, headingEnd Position) (string, Position) {
+	var b strings.Builder
+	end := headingEnd
+
+	for _, line := range lines {
+		b.WriteString(line.Text)
+		b.WriteString("\n")
+		if strings.TrimSpace(line.Text) != "" {
+			end = endOf(line)
 		}
-		if current != "" {
-			spec.Sections[current] += line + "\n"
+	}
+
+	return b.String(), end
+}
+
+func (spec *Spec) fillSection(section *Section, lines []sourceLine) {
+	switch section.Kind {
+	case SectionInputs:
+		spec.Inputs = append(spec.Inputs, parseItems(lines)...)
+	case SectionOutputs:
+		spec.Outputs = append(spec.Outputs, parseItems(lines)...)
+	case SectionBusinessRules:
+		spec.BusinessRules = append(spec.BusinessRules, parseBusinessRules(lines)...)
+	case SectionFlow:
+		spec.Flow = append(spec.Flow, parseFlow(lines)...)
+	case SectionDependencies:
+		spec.Dependencies = append(spec.Dependencies, parseDependencyList(lines)...)
+	case SectionErrors:
+		spec.Errors = append(spec.Errors, parseErrors(lines)...)
+	}
+}
+
+func parseList(lines []sourceLine) []listItem {
+	var items []listItem
+	indent := -1
+
+	for _, line := range lines {
+		if line.Fenced {
+			continue
 		}
-		
-		matches := re.FindAllStringSubmatch(line, -1)
-		for _, match := range matches {
-			spec.Links = append(spec.Links, SpecLink{
-				Title: match[1],
-				Path:  match[2],
+
+		if m := listItemRe.FindStringSubmatch(line.Text); m != nil && (indent < 0 || len(m[1]) <= indent) {
+			indent = len(m[1])
+			number, _ := strconv.Atoi(m[2])
+			items = append(items, listItem{
+				Number: number,
+				Text:   strings.TrimSpace(m[3]),
+				Lines:  []sourceLine{line},
+				Range:  lineRange(line, len(m[1]), len(line.Text)),
 			})
+			continue
+		}
+
+		if len(items) == 0 || strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+
+		current := &items[len(items)-1]
+		current.Lines = append(current.Lines, line)
+		current.Range.End = endOf(line)
+		if text := strings.TrimSpace(line.Text); text != "" {
+			current.Text += " " + text
 		}
 	}
 
-	return spec, nil
+	return items
+}
+
+func parseItems(lines []sourceLine) []Item {
+	var items []Item
+	for _, item := range parseList(lines) {
+		items = append(items, Item{Text: item.Text, Range: item.Range})
+	}
+	return items
+}
+
+func parseBusinessRules(lines []sourceLine) []BusinessRule {
+	var rules []BusinessRule
+	for _, item := range parseList(lines) {
+		number := item.Number
+		if number == 0 {
+			number = len(rules) + 1
+		}
+		rules = append(rules, BusinessRule{Number: number, Text: item.Text, Range: item.Range})
+	}
+	return rules
+}
+
+func parseDependencyList(lines []sourceLine) []Dependency {
+	var deps []Dependency
+	for _, item := range parseList(lines) {
+		dep := Dependency{Text: item.Text, Range: item.Range}
+		if m := linkRe.FindStringSubmatch(maskCodeSpans(item.Text)); m != nil {
+			dep.Title, dep.Path = m[1], m[2]
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+func parseErrors(lines []sourceLine) []ErrorDef {
+	var errs []ErrorDef
+	for _, item := range parseList(lines) {
+		def := ErrorDef{Name: item.Text, Range: item.Range}
+		if m := errorDefRe.FindStringSubmatch(item.Text); m != nil {
+			def.Name, def.Description = m[1], m[2]
+		} else if m := errorColonRe.FindStringSubmatch(item.Text); m != nil {
+			def.Name, def.Description = m[1], m[2]
+		}
+		def.Name = strings.Trim(strings.TrimSpace(def.Name), "`*")
+		errs = append(errs, def)
+	}
+	return errs
 }
 
 func ParseDependencies(specPath string) (*Spec, []Edge, error) {
@@ -57,24 +357,34 @@ func ParseDependencies(specPath string) (*Spec, []Edge, error) {
 		return nil, nil, err
 	}
 
-	edges := []Edge{}
-	re := regexp.MustCompile(`\[.*?\]\(([^)]+\.md)\)`)
+	return spec, dependencyEdges(specPath, spec), nil
+}
 
+func dependencyEdges(specPath string, spec *Spec) []Edge {
+	edges := []Edge{}
+	index := map[string]int{}
 	dir := filepath.Dir(specPath)
 
-	for _, section := range spec.Sections {
-		matches := re.FindAllStringSubmatch(section, -1)
-		for _, match := range matches {
-			refPath := match[1]
-			absPath := filepath.Join(dir, refPath)
-			normalized, _ := filepath.Abs(absPath)
+	add := func(target, kind string) {
+		normalized, _ := filepath.Abs(filepath.Join(dir, target))
 
-			edges = append(edges, Edge{
-				From: specPath,
-				To:   normalized,
-			})
+		i, ok := index[normalized]
+		if !ok {
+			index[normalized] = len(edges)
+			edges = append(edges, Edge{From: specPath, To: normalized, Kind: kind, Kinds: []string{kind}})
+			return
+		}
+		if !slices.Contains(edges[i].Kinds, kind) {
+			edges[i].Kinds = append(edges[i].Kinds, kind)
 		}
 	}
 
-	return spec, edges, nil
+	for _, link := range spec.Links {
+		add(link.Path, "depends")
+	}
+	for _, ref := range spec.Refs {
+		add(ref.Path, ref.Kind)
+	}
+
+	return edges
 }
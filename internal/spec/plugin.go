@@ -0,0 +1,379 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	PluginProtocolVersion = 1
+	DefaultPluginTimeout  = 30 * time.Second
+)
+
+type RulePlugin struct {
+	Name    string
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+type pluginInput struct {
+	Version int          `json:"version"`
+	Root    string       `json:"root"`
+	Specs   []pluginSpec `json:"specs"`
+	Graph   pluginGraph  `json:"graph"`
+}
+
+type pluginSpec struct {
+	Path          string             `json:"path"`
+	Title         string             `json:"title"`
+	Root          bool               `json:"root"`
+	Sections      []pluginSection    `json:"sections"`
+	Inputs        []pluginItem       `json:"inputs"`
+	Outputs       []pluginItem       `json:"outputs"`
+	BusinessRules []pluginRule       `json:"businessRules"`
+	Flow          []pluginStep       `json:"flow"`
+	Dependencies  []pluginDependency `json:"dependencies"`
+	Errors        []pluginError      `json:"errors"`
+	Content       string             `json:"content"`
+}
+
+type pluginSection struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Body      string `json:"body"`
+}
+
+type pluginItem struct {
+	Text string `json:"text"`
+	Line int    `json:"line"`
+}
+
+type pluginRule struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	Line   int    `json:"line"`
+}
+
+type pluginStep struct {
+	Number int         `json:"number"`
+	Text   string      `json:"text"`
+	Line   int         `json:"line"`
+	Calls  []pluginRef `json:"calls"`
+}
+
+type pluginRef struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Anchor string `json:"anchor,omitempty"`
+	Line   int    `json:"line"`
+}
+
+type pluginDependency struct {
+	Title string `json:"title"`
+	Path  string `json:"path"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+}
+
+type pluginError struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Line        int    `json:"line"`
+}
+
+type pluginGraph struct {
+	Roots []string     `json:"roots"`
+	Nodes []pluginNode `json:"nodes"`
+	Edges []pluginEdge `json:"edges"`
+}
+
+type pluginNode struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Exists bool   `json:"exists"`
+}
+
+type pluginEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  string   `json:"kind"`
+	Kinds []string `json:"kinds"`
+}
+
+type pluginFinding struct {
+	Rule      string `json:"rule"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Fix       string `json:"fix"`
+}
+
+func RunRulePlugins(ctx context.Context, project *Project, root string, plugins []RulePlugin) ([]Diagnostic, error) {
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+
+	input, err := json.Marshal(buildPluginInput(project, root))
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, plugin := range plugins {
+		found, err := runRulePlugin(ctx, plugin, input, root)
+		if err != nil {
+			return nil, fmt.Errorf("правило %s: %w", plugin.Name, err)
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+
+	diagnostics = filterPluginDiagnostics(project.Cache, diagnostics)
+	sortDiagnostics(diagnostics)
+	return diagnostics, nil
+}
+
+func runRulePlugin(ctx context.Context, plugin RulePlugin, input []byte, root string) ([]Diagnostic, error) {
+	timeout := plugin.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "SPEC_AGENT_ROOT="+root)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("превышен таймаут %s", timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, pluginFailure(err, stderr.String())
+	}
+
+	findings, decodeErr := decodeFindings(stdout.Bytes())
+	if err != nil && (decodeErr != nil || len(findings) == 0) {
+		return nil, pluginFailure(err, stderr.String())
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("некорректный вывод: %w", decodeErr)
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(findings))
+	for i, f := range findings {
+		d, err := findingDiagnostic(plugin.Name, root, f)
+		if err != nil {
+			return nil, fmt.Errorf("находка #%d: %w", i+1, err)
+		}
+		diagnostics = append(diagnostics, d)
+	}
+
+	return diagnostics, nil
+}
+
+func pluginFailure(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+func decodeFindings(data []byte) ([]pluginFinding, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("пустой stdout, ожидается JSON-массив находок (хотя бы [])")
+	}
+
+	var findings []pluginFinding
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &findings)
+		return findings, err
+	}
+
+	var wrapped struct {
+		Findings []pluginFinding `json:"findings"`
+	}
+	err := json.Unmarshal(data, &wrapped)
+	return wrapped.Findings, err
+}
+
+func findingDiagnostic(plugin, root string, f pluginFinding) (Diagnostic, error) {
+	if f.File == "" {
+		return Diagnostic{}, fmt.Errorf("не указан file")
+	}
+	if f.Message == "" {
+		return Diagnostic{}, fmt.Errorf("не указан message")
+	}
+
+	severity := f.Severity
+	switch severity {
+	case "":
+		severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return Diagnostic{}, fmt.Errorf("неизвестный severity %q (допустимо: error, warning)", f.Severity)
+	}
+
+	file := filepath.FromSlash(f.File)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+
+	rule := plugin
+	if f.Rule != "" {
+		rule = plugin + "/" + f.Rule
+	}
+
+	return Diagnostic{
+		RuleID:   rule,
+		Severity: severity,
+		File:     filepath.Clean(file),
+		Line:     max(f.Line, 1),
+		Column:   max(f.Column, 0),
+		End:      Position{Line: f.EndLine, Column: f.EndColumn},
+		Message:  f.Message,
+		Fix:      f.Fix,
+	}, nil
+}
+
+func filterPluginDiagnostics(cache *Cache, diagnostics []Diagnostic) []Diagnostic {
+	byFile := map[string][]Diagnostic{}
+	var order []string
+	for _, d := range diagnostics {
+		if _, ok := byFile[d.File]; !ok {
+			order = append(order, d.File)
+		}
+		byFile[d.File] = append(byFile[d.File], d)
+	}
+
+	var result []Diagnostic
+	for _, file := range order {
+		found := byFile[file]
+		if spec, err := cache.ParseFile(file); err == nil {
+			found = filterDisabled(spec, found, nil)
+		}
+		result = append(result, found...)
+	}
+	return result
+}
+
+func buildPluginInput(project *Project, root string) pluginInput {
+	rel := func(path string) string {
+		if r, err := filepath.Rel(root, path); err == nil {
+			return filepath.ToSlash(r)
+		}
+		return filepath.ToSlash(path)
+	}
+	resolve := func(from, link string) string {
+		return rel(filepath.Join(filepath.Dir(from), link))
+	}
+
+	roots := map[string]bool{}
+	input := pluginInput{
+		Version: PluginProtocolVersion,
+		Root:    root,
+		Specs:   []pluginSpec{},
+		Graph:   pluginGraph{Roots: []string{}, Nodes: []pluginNode{}, Edges: []pluginEdge{}},
+	}
+	for _, path := range project.RootSpecs {
+		roots[path] = true
+		input.Graph.Roots = append(input.Graph.Roots, rel(path))
+	}
+
+	for _, path := range sortedNodePaths(project.Graph) {
+		node := project.Graph.Nodes[path]
+		s, err := project.Cache.ParseFile(path)
+		input.Graph.Nodes = append(input.Graph.Nodes, pluginNode{
+			ID:     rel(node.ID),
+			Path:   rel(node.Path),
+			Type:   node.Type,
+			Exists: err == nil,
+		})
+		if err != nil {
+			continue
+		}
+
+		ps := pluginSpec{
+			Path:          rel(path),
+			Title:         s.Title,
+			Root:          roots[path],
+			Sections:      []pluginSection{},
+			Inputs:        pluginItems(s.Inputs),
+			Outputs:       pluginItems(s.Outputs),
+			BusinessRules: []pluginRule{},
+			Flow:          []pluginStep{},
+			Dependencies:  []pluginDependency{},
+			Errors:        []pluginError{},
+			Content:       s.Content,
+		}
+		for _, section := range s.Sections {
+			ps.Sections = append(ps.Sections, pluginSection{
+				Name:      section.Name,
+				Kind:      string(section.Kind),
+				StartLine: section.Heading.Start.Line,
+				EndLine:   section.Range.End.Line,
+				Body:      section.Body,
+			})
+		}
+		for _, rule := range s.BusinessRules {
+			ps.BusinessRules = append(ps.BusinessRules, pluginRule{Number: rule.Number, Text: rule.Text, Line: rule.Range.Start.Line})
+		}
+		for _, step := range s.Flow {
+			calls := []pluginRef{}
+			for _, ref := range step.Calls {
+				calls = append(calls, pluginRef{Kind: ref.Kind, Path: resolve(path, ref.Path), Anchor: ref.Anchor, Line: ref.Range.Start.Line})
+			}
+			ps.Flow = append(ps.Flow, pluginStep{Number: step.Number, Text: step.Text, Line: step.Range.Start.Line, Calls: calls})
+		}
+		for _, dep := range s.Dependencies {
+			target := ""
+			if dep.Path != "" {
+				target = resolve(path, dep.Path)
+			}
+			ps.Dependencies = append(ps.Dependencies, pluginDependency{Title: dep.Title, Path: target, Text: dep.Text, Line: dep.Range.Start.Line})
+		}
+		for _, e := range s.Errors {
+			ps.Errors = append(ps.Errors, pluginError{Name: e.Name, Description: e.Description, Line: e.Range.Start.Line})
+		}
+
+		input.Specs = append(input.Specs, ps)
+	}
+
+	for _, edge := range project.Graph.Edges {
+		input.Graph.Edges = append(input.Graph.Edges, pluginEdge{From: rel(edge.From), To: rel(edge.To), Kind: edge.Kind, Kinds: edge.Kinds})
+	}
+
+	return input
+}
+
+func pluginItems(items []Item) []pluginItem {
+	result := []pluginItem{}
+	for _, item := range items {
+		result = append(result, pluginItem{Text: item.Text, Line: item.Range.Start.Line})
+	}
+	return result
+}
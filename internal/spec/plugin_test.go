@@ -0,0 +1,198 @@
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const pluginModeEnv = "SPEC_AGENT_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(pluginModeEnv); mode != "" {
+		os.Exit(runTestPlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin makes the test binary act as a rule plugin selected by mode.
+func runTestPlugin(mode string) int {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if path := os.Getenv("SPEC_AGENT_TEST_INPUT"); path != "" {
+		if err := os.WriteFile(path, input, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	switch mode {
+	case "clean":
+		fmt.Println("[]")
+	case "findings":
+		fmt.Println(`[
+			{"rule": "owner", "file": "specs/api.md", "line": 3, "column": 1, "endLine": 3, "endColumn": 5, "severity": "warning", "message": "нет владельца", "fix": "добавьте владельца"},
+			{"file": "specs/service.md", "message": "сервис без владельца"},
+			{"rule": "owner", "file": "specs/quiet.md", "message": "отключено комментарием"}
+		]`)
+	case "wrapped-exit1":
+		fmt.Println(`{"findings": [{"file": "specs/api.md", "line": 1, "message": "ошибка"}]}`)
+		return 1
+	case "exit1-empty":
+		fmt.Println("[]")
+		fmt.Fprintln(os.Stderr, "плагин упал")
+		return 1
+	case "silent":
+	case "bad-severity":
+		fmt.Println(`[{"file": "specs/api.md", "severity": "fatal", "message": "ошибка"}]`)
+	case "exit2":
+		fmt.Println("[]")
+		return 2
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	}
+	return 0
+}
+
+func newPluginProject(t *testing.T) (*Project, string) {
+	t.Helper()
+	root := t.TempDir()
+
+	files := map[string]string{
+		"specs/api.md":     "# API\n\n## Responsibility\nПринимает запросы.\n\n## Dependencies\n- [Service](service.md)\n- [Quiet](quiet.md)\n",
+		"specs/service.md": "# Service\n\n## Responsibility\nДелает работу.\n",
+		"specs/quiet.md":   "# Quiet\n\n<!-- spec-agent-disable house/owner -->\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	project, err := LoadProject([]string{filepath.Join(root, "specs")}, DiscoverOptions{Root: root}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project, root
+}
+
+func runTestRule(t *testing.T, mode string, timeout time.Duration) ([]Diagnostic, error) {
+	t.Helper()
+	t.Setenv(pluginModeEnv, mode)
+
+	project, root := newPluginProject(t)
+	plugin := RulePlugin{Name: "house", Command: os.Args[0], Timeout: timeout}
+	return RunRulePlugins(context.Background(), project, root, []RulePlugin{plugin})
+}
+
+func TestRulePluginClean(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.json")
+	t.Setenv("SPEC_AGENT_TEST_INPUT", input)
+
+	diagnostics, err := runTestRule(t, "clean", 0)
+	if err != nil {
+		t.Fatalf("чистый запуск вернул ошибку: %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("ожидалось 0 находок, получено %+v", diagnostics)
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload pluginInput
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("stdin плагина не JSON: %v", err)
+	}
+	if payload.Version != PluginProtocolVersion || payload.Root == "" {
+		t.Errorf("неожиданный заголовок stdin: version=%d root=%q", payload.Version, payload.Root)
+	}
+	if len(payload.Specs) != 3 || len(payload.Graph.Nodes) != 3 || len(payload.Graph.Edges) != 2 {
+		t.Fatalf("ожидалось 3 спеки, 3 узла и 2 ребра: %d, %d, %d", len(payload.Specs), len(payload.Graph.Nodes), len(payload.Graph.Edges))
+	}
+	if len(payload.Graph.Roots) != 1 || payload.Graph.Roots[0] != "specs/api.md" {
+		t.Errorf("Graph.Roots = %v, ожидалось [specs/api.md]", payload.Graph.Roots)
+	}
+	api := payload.Specs[0]
+	if api.Path != "specs/api.md" || !api.Root || len(api.Dependencies) != 2 || api.Dependencies[0].Path != "specs/service.md" {
+		t.Errorf("неожиданная спека в stdin: %+v", api)
+	}
+}
+
+func TestRulePluginFindings(t *testing.T) {
+	diagnostics, err := runTestRule(t, "findings", 0)
+	if err != nil {
+		t.Fatalf("плагин вернул ошибку: %v", err)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("ожидалось 2 находки (третья отключена комментарием), получено %+v", diagnostics)
+	}
+
+	d := diagnostics[0]
+	if d.RuleID != "house/owner" || d.Severity != SeverityWarning || filepath.Base(d.File) != "api.md" ||
+		d.Line != 3 || d.Column != 1 || d.End != (Position{Line: 3, Column: 5}) || d.Fix != "добавьте владельца" {
+		t.Errorf("неожиданная находка: %+v", d)
+	}
+	if !filepath.IsAbs(d.File) {
+		t.Errorf("путь находки должен быть абсолютным: %s", d.File)
+	}
+
+	d = diagnostics[1]
+	if d.RuleID != "house" || d.Severity != SeverityError || d.Line != 1 || filepath.Base(d.File) != "service.md" {
+		t.Errorf("значения по умолчанию не применены: %+v", d)
+	}
+}
+
+func TestRulePluginExitOneWithFindings(t *testing.T) {
+	diagnostics, err := runTestRule(t, "wrapped-exit1", 0)
+	if err != nil {
+		t.Fatalf("код 1 с находками должен приниматься: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Message != "ошибка" {
+		t.Errorf("находки из {\"findings\": …} не разобраны: %+v", diagnostics)
+	}
+}
+
+func TestRulePluginFailures(t *testing.T) {
+	tests := []struct {
+		mode    string
+		timeout time.Duration
+		want    string
+	}{
+		{"exit1-empty", 0, "плагин упал"},
+		{"exit2", 0, "exit status 2"},
+		{"silent", 0, "пустой stdout"},
+		{"bad-severity", 0, `неизвестный severity "fatal"`},
+		{"sleep", 200 * time.Millisecond, "превышен таймаут"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			start := time.Now()
+			diagnostics, err := runTestRule(t, tt.mode, tt.timeout)
+			if err == nil {
+				t.Fatalf("ожидалась ошибка, получено %+v", diagnostics)
+			}
+			if !strings.HasPrefix(err.Error(), "правило house: ") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ошибка %q не содержит %q", err, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("плагин остановлен через %s", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type Project struct {
+	Files     []string
+	RootSpecs []string
+	Graph     *Graph
+	Cache     *Cache
+	Lint      LintOptions
+}
+
+type ProjectLoader func() (*Project, error)
+
+func LoadProject(roots []string, discover DiscoverOptions, cache *Cache) (*Project, error) {
+	if cache == nil {
+		cache = NewCache()
+	}
+
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("в config.yaml не указаны roots")
+	}
+
+	files, err := FindSpecs(roots, discover)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("не найдено ни одной спецификации рядом с roots")
+	}
+
+	referenced := cache.CollectAllReferences(files)
+	rootSpecs := FindRootSpecs(files, referenced)
+	if len(rootSpecs) == 0 {
+		return nil, fmt.Errorf("не удалось определить корневые спецификации")
+	}
+
+	graph, err := cache.BuildGraphFromRoots(rootSpecs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Project{
+		Files:     files,
+		RootSpecs: rootSpecs,
+		Graph:     graph,
+		Cache:     cache,
+	}, nil
+}
+
+func (p *Project) Specs() map[string]*Spec {
+	return parseGraphSpecs(p.Graph, p.Cache, 0)
+}
+
+func (p *Project) Paths() []string {
+	return sortedNodePaths(p.Graph)
+}
+
+func RelPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
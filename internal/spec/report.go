@@ -0,0 +1,293 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatSARIF  = "sarif"
+	FormatGitHub = "github"
+)
+
+var ReportFormats = []string{FormatText, FormatJSON, FormatSARIF, FormatGitHub}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type ReportOptions struct {
+	Format string
+	Root   string
+}
+
+type DiagnosticsJSON struct {
+	Diagnostics []DiagnosticJSON `json:"diagnostics"`
+	Errors      int              `json:"errors"`
+	Warnings    int              `json:"warnings"`
+}
+
+type DiagnosticJSON struct {
+	Rule     string    `json:"rule"`
+	Severity string    `json:"severity"`
+	File     string    `json:"file"`
+	Range    RangeJSON `json:"range"`
+	Message  string    `json:"message"`
+	Fix      string    `json:"fix,omitempty"`
+}
+
+type RangeJSON struct {
+	Start PositionJSON `json:"start"`
+	End   PositionJSON `json:"end"`
+}
+
+type PositionJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func WriteDiagnostics(w io.Writer, diagnostics []Diagnostic, opts ReportOptions) error {
+	switch opts.Format {
+	case "", FormatText:
+		return writeText(w, diagnostics)
+	case FormatJSON:
+		return writeJSON(w, diagnostics, opts.Root)
+	case FormatSARIF:
+		return writeSARIF(w, diagnostics, opts.Root)
+	case FormatGitHub:
+		return writeGitHub(w, diagnostics, opts.Root)
+	default:
+		return fmt.Errorf("неизвестный формат: %s (допустимо: %s)", opts.Format, strings.Join(ReportFormats, ", "))
+	}
+}
+
+func DiagnosticLocation(d Diagnostic) string {
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", RelPath(d.File), d.Line, d.Column)
+	}
+	return fmt.Sprintf("%s:%d", RelPath(d.File), d.Line)
+}
+
+func CountSeverities(diagnostics []Diagnostic) (errors, warnings int) {
+	for _, d := range diagnostics {
+		switch d.Severity {
+		case SeverityError:
+			errors++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+func writeText(w io.Writer, diagnostics []Diagnostic) error {
+	for _, d := range diagnostics {
+		if _, err := fmt.Fprintf(w, "%s: %s [%s] %s\n", DiagnosticLocation(d), d.Severity, d.RuleID, d.Message); err != nil {
+			return err
+		}
+		if d.Fix != "" {
+			if _, err := fmt.Fprintf(w, "    💡 %s\n", d.Fix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func NewDiagnosticsJSON(diagnostics []Diagnostic, root string) DiagnosticsJSON {
+	errors, warnings := CountSeverities(diagnostics)
+	report := DiagnosticsJSON{
+		Diagnostics: []DiagnosticJSON{},
+		Errors:      errors,
+		Warnings:    warnings,
+	}
+
+	for _, d := range diagnostics {
+		r := d.Range()
+		report.Diagnostics = append(report.Diagnostics, DiagnosticJSON{
+			Rule:     d.RuleID,
+			Severity: d.Severity,
+			File:     reportPath(root, d.File),
+			Range: RangeJSON{
+				Start: PositionJSON{Line: r.Start.Line, Column: r.Start.Column},
+				End:   PositionJSON{Line: r.End.Line, Column: r.End.Column},
+			},
+			Message: d.Message,
+			Fix:     d.Fix,
+		})
+	}
+
+	return report
+}
+
+func writeJSON(w io.Writer, diagnostics []Diagnostic, root string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDiagnosticsJSON(diagnostics, root))
+}
+
+func writeSARIF(w io.Writer, diagnostics []Diagnostic, root string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "spec-agent",
+			InformationURI: "https://github.com/SmirnovND/spec-agent",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := map[string]bool{}
+	for _, d := range diagnostics {
+		rules[d.RuleID] = true
+
+		level := "warning"
+		if d.Severity == SeverityError {
+			level = "error"
+		}
+
+		text := d.Message
+		if d.Fix != "" {
+			text += "\n" + d.Fix
+		}
+
+		artifact := sarifArtifact{URI: reportPath(root, d.File), URIBaseID: "%SRCROOT%"}
+		if filepath.IsAbs(d.File) && artifact.URI == filepath.ToSlash(d.File) {
+			artifact = sarifArtifact{URI: (&url.URL{Scheme: "file", Path: artifact.URI}).String()}
+		}
+
+		r := d.Range()
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  d.RuleID,
+			Level:   level,
+			Message: sarifMessage{Text: text},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: artifact,
+				Region: sarifRegion{
+					StartLine:   r.Start.Line,
+					StartColumn: r.Start.Column,
+					EndLine:     r.End.Line,
+					EndColumn:   r.End.Column,
+				},
+			}}},
+		})
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: "2.1.0", Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+func writeGitHub(w io.Writer, diagnostics []Diagnostic, root string) error {
+	for _, d := range diagnostics {
+		command := "warning"
+		if d.Severity == SeverityError {
+			command = "error"
+		}
+
+		message := d.Message
+		if d.Fix != "" {
+			message += "\n" + d.Fix
+		}
+
+		r := d.Range()
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,endLine=%d,endColumn=%d,title=%s::%s\n",
+			command,
+			githubProperty(reportPath(root, d.File)),
+			r.Start.Line, r.Start.Column, r.End.Line, r.End.Column,
+			githubProperty("spec-agent "+d.RuleID),
+			githubData(message),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reportPath(root, path string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,76 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+type SearchEntry struct {
+	Title    string   `json:"t"`
+	URL      string   `json:"u"`
+	Headings []string `json:"h,omitempty"`
+	Rules    []string `json:"r,omitempty"`
+	Errors   []string `json:"e,omitempty"`
+}
+
+func BuildSearchIndex(specs map[string]*Spec, root string) []SearchEntry {
+	entries := make([]SearchEntry, 0, len(specs))
+
+	for path, spec := range specs {
+		entry := SearchEntry{
+			Title: spec.Title,
+			URL:   PageFilename(root, path),
+		}
+		if entry.Title == "" {
+			entry.Title = filepath.Base(path)
+		}
+
+		for _, heading := range spec.Headings {
+			if heading.Level >= 2 && heading.Text != "" {
+				entry.Headings = append(entry.Headings, heading.Text)
+			}
+		}
+
+		for _, rule := range spec.BusinessRules {
+			entry.Rules = append(entry.Rules, rule.Text)
+		}
+
+		for _, e := range spec.Errors {
+			text := e.Name
+			if e.Description != "" {
+				text += " — " + e.Description
+			}
+			entry.Errors = append(entry.Errors, text)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+
+	return entries
+}
+
+func writeSearchAssets(out *buildWriter, specs map[string]*Spec, root string) error {
+	data, err := json.Marshal(BuildSearchIndex(specs, root))
+	if err != nil {
+		return fmt.Errorf("не удалось построить поисковый индекс: %w", err)
+	}
+
+	files := map[string][]byte{
+		"search-index.json": data,
+		"search-index.js":   []byte("window.SPEC_SEARCH_INDEX = " + string(data) + ";\n"),
+	}
+
+	for name, content := range files {
+		if err := out.Write(name, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package spec
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+type SectionKind string
+
+const (
+	SectionUnknown        SectionKind = ""
+	SectionResponsibility SectionKind = "responsibility"
+	SectionInputs         SectionKind = "inputs"
+	SectionOutputs        SectionKind = "outputs"
+	SectionBusinessRules  SectionKind = "business_rules"
+	SectionFlow           SectionKind = "flow"
+	SectionDependencies   SectionKind = "dependencies"
+	SectionErrors         SectionKind = "errors"
+	SectionNotes          SectionKind = "notes"
+)
+
+const (
+	LanguageEnglish = "en"
+	LanguageRussian = "ru"
+)
+
+var SectionKinds = []SectionKind{
+	SectionResponsibility,
+	SectionInputs,
+	SectionOutputs,
+	SectionBusinessRules,
+	SectionFlow,
+	SectionDependencies,
+	SectionErrors,
+	SectionNotes,
+}
+
+var canonicalSectionNames = map[string]map[SectionKind]string{
+	LanguageEnglish: {
+		SectionResponsibility: "Responsibility",
+		SectionInputs:         "Inputs",
+		SectionOutputs:        "Outputs",
+		SectionBusinessRules:  "Business Rules",
+		SectionFlow:           "Flow",
+		SectionDependencies:   "Dependencies",
+		SectionErrors:         "Errors",
+		SectionNotes:          "Notes",
+	},
+	LanguageRussian: {
+		SectionResponsibility: "Ответственность",
+		SectionInputs:         "Входные данные",
+		SectionOutputs:        "Выходные данные",
+		SectionBusinessRules:  "Бизнес-правила",
+		SectionFlow:           "Поток",
+		SectionDependencies:   "Зависимости",
+		SectionErrors:         "Ошибки",
+		SectionNotes:          "Примечания",
+	},
+}
+
+var DefaultSectionAliases = map[SectionKind][]string{
+	SectionResponsibility: {"Назначение"},
+	SectionInputs:         {"Input", "Contracts", "Contract", "Входы", "Вход", "Контракты", "Контракт"},
+	SectionOutputs:        {"Output", "Выходы", "Выход", "Результат"},
+	SectionBusinessRules:  {"Rules", "Правила", "Бизнес правила", "Ограничения"},
+	SectionFlow:           {"Алгоритм", "Сценарий", "Шаги"},
+	SectionErrors:         {"Error Handling"},
+	SectionNotes:          {"Note", "Заметки", "Контекст"},
+}
+
+type SectionNames struct {
+	Language  string
+	kinds     map[string]SectionKind
+	canonical map[SectionKind]string
+}
+
+var defaultSectionNames, _ = NewSectionNames(LanguageEnglish, nil)
+
+func NewSectionNames(language string, aliases map[string][]string) (*SectionNames, error) {
+	if language == "" {
+		language = LanguageEnglish
+	}
+
+	canonical, ok := canonicalSectionNames[language]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный язык секций %q (допустимо: en, ru)", language)
+	}
+
+	names := &SectionNames{
+		Language:  language,
+		kinds:     map[string]SectionKind{},
+		canonical: canonical,
+	}
+
+	for _, table := range canonicalSectionNames {
+		for kind, name := range table {
+			names.kinds[normalizeSectionName(name)] = kind
+		}
+	}
+	for kind, list := range DefaultSectionAliases {
+		for _, alias := range list {
+			names.kinds[normalizeSectionName(alias)] = kind
+		}
+	}
+
+	keys := make([]string, 0, len(aliases))
+	for key := range aliases {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kind, ok := ParseSectionKind(key)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный тип секции %q", key)
+		}
+		for _, alias := range aliases[key] {
+			names.kinds[normalizeSectionName(alias)] = kind
+		}
+	}
+
+	return names, nil
+}
+
+func DefaultSectionNames() *SectionNames {
+	return defaultSectionNames
+}
+
+func sectionNamesOrDefault(names *SectionNames) *SectionNames {
+	if names == nil {
+		return defaultSectionNames
+	}
+	return names
+}
+
+func (n *SectionNames) equal(other *SectionNames) bool {
+	n, other = sectionNamesOrDefault(n), sectionNamesOrDefault(other)
+	return n == other || n.Language == other.Language && maps.Equal(n.kinds, other.kinds)
+}
+
+func ParseSectionKind(s string) (SectionKind, bool) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+	for _, kind := range SectionKinds {
+		if string(kind) == key {
+			return kind, true
+		}
+	}
+	return SectionUnknown, false
+}
+
+func (n *SectionNames) Kind(heading string) SectionKind {
+	n = sectionNamesOrDefault(n)
+	return n.kinds[normalizeSectionName(heading)]
+}
+
+func (n *SectionNames) Name(kind SectionKind) string {
+	n = sectionNamesOrDefault(n)
+	return n.canonical[kind]
+}
+
+func sectionLanguage(sections []Section, fallback string) string {
+	votes := map[string]int{}
+	for _, section := range sections {
+		if section.Kind == SectionUnknown {
+			continue
+		}
+		switch cyrillic, latin := countLetters(section.Name, LanguageRussian); {
+		case cyrillic > 0 && latin == 0:
+			votes[LanguageRussian]++
+		case latin > 0 && cyrillic == 0:
+			votes[LanguageEnglish]++
+		}
+	}
+
+	for language := range canonicalSectionNames {
+		if votes[language] > votes[fallback] {
+			fallback = language
+		}
+	}
+	return fallback
+}
+
+func normalizeSectionName(name string) string {
+	name = strings.TrimRight(strings.TrimSpace(name), ":")
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func NormalizeSectionHeadings(spec *Spec, names *SectionNames) (string, int) {
+	lines := strings.Split(spec.Content, "\n")
+	fixed := 0
+
+	for _, section := range spec.Sections {
+		canonical := names.Name(section.Kind)
+		if canonical == "" || section.Name == canonical {
+			continue
+		}
+
+		i := section.Heading.Start.Line - 1
+		heading := "## " + canonical
+		if strings.HasSuffix(lines[i], "\r") {
+			heading += "\r"
+		}
+		lines[i] = heading
+		fixed++
+	}
+
+	return strings.Join(lines, "\n"), fixed
+}
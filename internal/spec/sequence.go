@@ -0,0 +1,238 @@
+package spec
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sequenceColumnWidth = 200
+	sequenceBoxWidth    = 170
+	sequenceBoxHeight   = 32
+	sequenceRowStep     = 40
+	sequencePadding     = 20
+	sequenceLabelLimit  = 34
+)
+
+type Sequence struct {
+	Participants []Participant
+	Messages     []SequenceMessage
+}
+
+type Participant struct {
+	ID    string
+	Title string
+	Path  string
+}
+
+type SequenceMessage struct {
+	From  string
+	To    string
+	Type  string
+	Label string
+}
+
+func BuildSequence(specPath string, depth int, cache *Cache) (*Sequence, error) {
+	return buildSequence(specPath, depth, cache.ParseFile)
+}
+
+type sequenceBuilder struct {
+	seq   *Sequence
+	ids   map[string]string
+	parse func(string) (*Spec, error)
+	stack map[string]bool
+}
+
+func buildSequence(specPath string, depth int, parse func(string) (*Spec, error)) (*Sequence, error) {
+	abs, err := filepath.Abs(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := parse(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &sequenceBuilder{
+		seq:   &Sequence{},
+		ids:   map[string]string{},
+		parse: parse,
+		stack: map[string]bool{abs: true},
+	}
+	b.participant(abs, spec)
+	b.walk(abs, spec, depth)
+
+	return b.seq, nil
+}
+
+func (b *sequenceBuilder) participant(path string, spec *Spec) string {
+	if id, ok := b.ids[path]; ok {
+		return id
+	}
+
+	title := filepath.Base(path)
+	if spec != nil && spec.Title != "" {
+		title = spec.Title
+	}
+
+	id := fmt.Sprintf("P%d", len(b.seq.Participants)+1)
+	b.ids[path] = id
+	b.seq.Participants = append(b.seq.Participants, Participant{ID: id, Title: title, Path: path})
+	return id
+}
+
+func (b *sequenceBuilder) walk(path string, spec *Spec, depth int) {
+	self := b.ids[path]
+	dir := filepath.Dir(path)
+
+	for _, step := range spec.Flow {
+		if len(step.Calls) == 0 {
+			b.seq.Messages = append(b.seq.Messages, SequenceMessage{
+				From:  self,
+				To:    self,
+				Type:  "note",
+				Label: fmt.Sprintf("%d. %s", step.Number, step.Text),
+			})
+			continue
+		}
+
+		for _, call := range step.Calls {
+			target, _ := filepath.Abs(filepath.Join(dir, call.Path))
+			targetSpec, err := b.parse(target)
+			if err != nil {
+				targetSpec = nil
+			}
+			id := b.participant(target, targetSpec)
+
+			label := call.Kind
+			if call.Anchor != "" {
+				label += " " + call.Anchor
+			} else if step.Text != "" {
+				label += ": " + step.Text
+			}
+
+			b.seq.Messages = append(b.seq.Messages, SequenceMessage{
+				From:  self,
+				To:    id,
+				Type:  "call",
+				Label: fmt.Sprintf("%d. %s", step.Number, label),
+			})
+
+			if depth <= 0 || targetSpec == nil || b.stack[target] {
+				continue
+			}
+
+			b.stack[target] = true
+			b.walk(target, targetSpec, depth-1)
+			delete(b.stack, target)
+
+			b.seq.Messages = append(b.seq.Messages, SequenceMessage{
+				From:  id,
+				To:    self,
+				Type:  "return",
+				Label: "return",
+			})
+		}
+	}
+}
+
+func (s *Sequence) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("sequenceDiagram\n")
+
+	for _, p := range s.Participants {
+		fmt.Fprintf(&b, "    participant %s as %s\n", p.ID, mermaidText(p.Title))
+	}
+
+	for _, m := range s.Messages {
+		switch m.Type {
+		case "note":
+			fmt.Fprintf(&b, "    Note over %s: %s\n", m.From, mermaidText(m.Label))
+		case "return":
+			fmt.Fprintf(&b, "    %s-->>%s: %s\n", m.From, m.To, mermaidText(m.Label))
+		default:
+			fmt.Fprintf(&b, "    %s->>%s: %s\n", m.From, m.To, mermaidText(m.Label))
+		}
+	}
+
+	return b.String()
+}
+
+func mermaidText(s string) string {
+	r := strings.NewReplacer(";", ",", "#", "", "\n", " ", "\r", "")
+	return strings.TrimSpace(r.Replace(s))
+}
+
+func (s *Sequence) SVG() string {
+	if len(s.Participants) == 0 {
+		return ""
+	}
+
+	width := len(s.Participants)*sequenceColumnWidth + 2*sequencePadding
+	top := sequencePadding + sequenceBoxHeight
+	height := top + (len(s.Messages)+1)*sequenceRowStep + sequencePadding
+
+	centers := map[string]int{}
+	for i, p := range s.Participants {
+		centers[p.ID] = sequencePadding + i*sequenceColumnWidth + sequenceColumnWidth/2
+	}
+
+	seed := fmt.Sprint(len(s.Messages))
+	for _, p := range s.Participants {
+		seed += "\x00" + p.Path
+	}
+	id := svgIDPrefix(seed)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="spec-sequence" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="Диаграмма последовательности">
+`, width, height)
+	fmt.Fprintf(&b, `<defs><marker id="%s-seq-arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#495057"/></marker></defs>
+`, id)
+
+	for _, p := range s.Participants {
+		x := centers[p.ID]
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#adb5bd" stroke-dasharray="4 3"/>
+`, x, top, x, height-sequencePadding)
+		fmt.Fprintf(&b, `<g><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#667eea"/><text x="%d" y="%d" font-size="13" text-anchor="middle" fill="#ffffff">%s</text></g>
+`, html.EscapeString(filepath.Base(p.Path)), x-sequenceBoxWidth/2, sequencePadding, sequenceBoxWidth, sequenceBoxHeight,
+			x, sequencePadding+sequenceBoxHeight/2+5, html.EscapeString(truncateLabel(p.Title, 22)))
+	}
+
+	for i, m := range s.Messages {
+		y := top + (i+1)*sequenceRowStep
+		from, to := centers[m.From], centers[m.To]
+		label := html.EscapeString(truncateLabel(m.Label, sequenceLabelLimit))
+
+		switch {
+		case m.Type == "note":
+			fmt.Fprintf(&b, `<g class="note"><title>%s</title><rect x="%d" y="%d" width="%d" height="24" fill="#fff9db" stroke="#fab005"/><text x="%d" y="%d" font-size="11" text-anchor="middle" fill="#333333">%s</text></g>
+`, html.EscapeString(m.Label), from-sequenceBoxWidth/2-5, y-16, sequenceBoxWidth+10, from, y, label)
+		case from == to:
+			fmt.Fprintf(&b, `<g class="message"><path d="M %d %d h 30 v 14 h -30" fill="none" stroke="#495057" stroke-width="1.5" marker-end="url(#%s-seq-arrow)"/>
+<text x="%d" y="%d" font-size="11" fill="#333333">%s</text></g>
+`, from, y-7, id, from+36, y+3, label)
+		default:
+			dash := ""
+			if m.Type == "return" {
+				dash = ` stroke-dasharray="5 4"`
+			}
+			fmt.Fprintf(&b, `<g class="message"><line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#495057" stroke-width="1.5"%s marker-end="url(#%s-seq-arrow)"/>
+<title>%s</title><text x="%d" y="%d" font-size="11" text-anchor="middle" fill="#333333">%s</text></g>
+`, from, y, to, y, dash, id, html.EscapeString(m.Label), (from+to)/2, y-6, label)
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func truncateLabel(label string, limit int) string {
+	runes := []rune(label)
+	if len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	return label
+}
@@ -0,0 +1,117 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type VerifyOptions struct {
+	Roots    []string
+	Config   string
+	Discover DiscoverOptions
+}
+
+func VerifyRoots(opts VerifyOptions) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, root := range opts.Roots {
+		line := configLine(opts.Config, slashRel(opts.Discover.Root, root))
+
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "unresolved-root",
+				Severity: SeverityError,
+				File:     opts.Config,
+				Line:     line,
+				Message:  fmt.Sprintf("каталог из roots не существует: %s", RelPath(root)),
+				Fix:      "исправьте путь в roots или создайте каталог",
+			})
+			continue
+		}
+
+		files, err := FindSpecs([]string{root}, opts.Discover)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "unresolved-root",
+				Severity: SeverityError,
+				File:     opts.Config,
+				Line:     line,
+				Message:  fmt.Sprintf("не удалось обойти каталог %s: %v", RelPath(root), err),
+			})
+			continue
+		}
+		if len(files) == 0 {
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "unresolved-root",
+				Severity: SeverityWarning,
+				File:     opts.Config,
+				Line:     line,
+				Message:  fmt.Sprintf("в каталоге %s нет ни одной спецификации", RelPath(root)),
+				Fix:      "добавьте спецификацию или уберите каталог из roots (см. spec-agent ls -a)",
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+func VerifyGraph(project *Project) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, path := range sortedNodePaths(project.Graph) {
+		spec, err := project.Cache.ParseFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				RuleID:   "unreadable-file",
+				Severity: SeverityError,
+				File:     path,
+				Line:     1,
+				Message:  fmt.Sprintf("не удалось прочитать спецификацию: %v", err),
+				Fix:      "проверьте права доступа к файлу",
+			})
+			continue
+		}
+
+		diagnostics = append(diagnostics, filterDisabled(spec, checkBrokenLinks(spec), nil)...)
+	}
+
+	for _, path := range project.Files {
+		if _, ok := project.Graph.Nodes[path]; ok {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			RuleID:   "unreachable-spec",
+			Severity: SeverityWarning,
+			File:     path,
+			Line:     1,
+			Message:  "спецификация недостижима из корневых: ссылки на неё замкнуты в цикл",
+			Fix:      "разорвите цикл ссылок, чтобы у дерева появился корень",
+		})
+	}
+
+	sortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+func configLine(config, root string) int {
+	data, err := os.ReadFile(config)
+	if err != nil {
+		return 1
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		value := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-")), `"'`)
+		if value == root || filepath.Clean(value) == filepath.Clean(root) {
+			return i + 1
+		}
+	}
+	return 1
+}
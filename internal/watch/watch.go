@@ -0,0 +1,109 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+type Poller struct {
+	paths    []string
+	interval time.Duration
+
+	mu    sync.Mutex
+	state map[string]fileState
+}
+
+func NewPoller(paths []string, interval time.Duration) *Poller {
+	p := &Poller{
+		paths:    paths,
+		interval: interval,
+	}
+	p.state = p.snapshot()
+	return p
+}
+
+func (p *Poller) Run(ctx context.Context, onChange func(changed []string)) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := p.Poll(); len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+func (p *Poller) Poll() []string {
+	next := p.snapshot()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changed []string
+	for path, st := range next {
+		prev, ok := p.state[path]
+		if !ok || !prev.modTime.Equal(st.modTime) || prev.size != st.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range p.state {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	p.state = next
+	sort.Strings(changed)
+	return changed
+}
+
+func (p *Poller) Mark(paths ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, path := range paths {
+		abs, _ := filepath.Abs(path)
+		info, err := os.Stat(abs)
+		if err != nil {
+			delete(p.state, abs)
+			continue
+		}
+		p.state[abs] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+}
+
+func (p *Poller) snapshot() map[string]fileState {
+	state := map[string]fileState{}
+
+	for _, root := range p.paths {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			abs, _ := filepath.Abs(path)
+			state[abs] = fileState{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+
+	return state
+}
@@ -0,0 +1,213 @@
+package specagent
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/SmirnovND/spec-agent/internal/config"
+	agentfs "github.com/SmirnovND/spec-agent/internal/fs"
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+// Options select the configuration a project is loaded with.
+type Options struct {
+	// Config is an already loaded configuration; ConfigPath and Dir are
+	// ignored when it is set.
+	Config *Config
+	// ConfigPath is an explicit path to config.yaml. When empty,
+	// $SPEC_AGENT_CONFIG is used, then discovery upwards from Dir.
+	ConfigPath string
+	// Dir is the directory discovery starts from, the working directory
+	// by default.
+	Dir string
+	// SectionLanguage overrides sections.language ("en" or "ru").
+	SectionLanguage string
+	// Cache reuses parsed specs between loads; call Cache.Invalidate for
+	// changed files. A fresh cache is used when nil. Open drops the cached
+	// specs when the section names differ from those they were parsed with.
+	Cache *Cache
+}
+
+// LoadConfig finds and strictly decodes config.yaml.
+func LoadConfig(opts Options) (*Config, error) {
+	if opts.Config != nil {
+		return opts.Config, nil
+	}
+
+	cfg, err := loadConfigFile(opts)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить config.yaml: %w", err)
+	}
+	return cfg, nil
+}
+
+// SectionNamesFor returns the section heading names configured in sections
+// of cfg. A non-empty language overrides sections.language; a nil cfg yields
+// the default names.
+func SectionNamesFor(cfg *Config, language string) (*SectionNames, error) {
+	var aliases map[string][]string
+	if cfg != nil {
+		aliases = cfg.Sections.Aliases
+		if language == "" {
+			language = cfg.Sections.Language
+		}
+	}
+
+	names, err := spec.NewSectionNames(language, aliases)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная секция sections в config.yaml: %w", err)
+	}
+	return names, nil
+}
+
+func loadConfigFile(opts Options) (*Config, error) {
+	if opts.ConfigPath != "" || opts.Dir == "" {
+		return config.Load(opts.ConfigPath)
+	}
+	if env := os.Getenv(config.EnvVar); env != "" {
+		return config.LoadFile(env)
+	}
+
+	found, err := config.Discover(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadFile(found)
+}
+
+// ValidateConfig reports every problem in cfg: missing roots and templates,
+// unknown theme, bad glob patterns, section names and lint settings.
+func ValidateConfig(cfg *Config) []error {
+	var problems []error
+
+	if len(cfg.Roots) == 0 {
+		problems = append(problems, fmt.Errorf("roots: не указан ни один каталог"))
+	}
+	for _, root := range cfg.Roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Errorf("roots: каталог %s не существует", spec.RelPath(root)))
+		}
+	}
+
+	if cfg.Export.Templates != "" {
+		if info, err := os.Stat(cfg.Export.Templates); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Errorf("export.templates: каталог %s не существует", spec.RelPath(cfg.Export.Templates)))
+		}
+	}
+	if cfg.Export.Theme != "" {
+		if _, err := fs.Stat(agentfs.Templates(cfg.TemplatesDir()), "themes/"+cfg.Export.Theme+".css"); err != nil {
+			problems = append(problems, fmt.Errorf("export.theme: тема %q не найдена", cfg.Export.Theme))
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, cfg.Specs.Include...), cfg.Specs.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			problems = append(problems, fmt.Errorf("specs: некорректный шаблон %q: %w", pattern, err))
+		}
+	}
+
+	if _, err := spec.NewSectionNames(cfg.Sections.Language, cfg.Sections.Aliases); err != nil {
+		problems = append(problems, fmt.Errorf("sections: %w", err))
+	}
+
+	if _, err := LintOptionsFor(cfg); err != nil {
+		problems = append(problems, err)
+	}
+
+	names := map[string]bool{}
+	for i, rule := range cfg.Rules {
+		switch {
+		case rule.Name == "":
+			problems = append(problems, fmt.Errorf("rules[%d]: не указано имя", i))
+		case names[rule.Name]:
+			problems = append(problems, fmt.Errorf("rules: правило %q указано дважды", rule.Name))
+		}
+		names[rule.Name] = true
+
+		if rule.Command == "" {
+			problems = append(problems, fmt.Errorf("rules[%d]: не указана команда", i))
+		} else if _, err := exec.LookPath(rule.Command); err != nil {
+			problems = append(problems, fmt.Errorf("rules[%d]: команда %s не найдена или не исполняемая", i, spec.RelPath(rule.Command)))
+		}
+		if rule.Timeout < 0 {
+			problems = append(problems, fmt.Errorf("rules[%d]: timeout не может быть отрицательным", i))
+		}
+	}
+
+	return problems
+}
+
+// DiscoverOptionsFor returns the spec discovery settings of cfg.
+func DiscoverOptionsFor(cfg *Config) DiscoverOptions {
+	return spec.DiscoverOptions{
+		Root:             cfg.Root,
+		Include:          cfg.Specs.Include,
+		Exclude:          cfg.Specs.Exclude,
+		NoDefaultExclude: cfg.Specs.DefaultExcludes != nil && !*cfg.Specs.DefaultExcludes,
+		NoGitignore:      cfg.Specs.Gitignore != nil && !*cfg.Specs.Gitignore,
+	}
+}
+
+// LintOptionsFor returns the lint settings of cfg.
+func LintOptionsFor(cfg *Config) (LintOptions, error) {
+	language := cfg.Lint.Language
+
+	switch language.Require {
+	case "", spec.LanguageRussian, spec.LanguageEnglish, spec.LanguageOff:
+	default:
+		return spec.LintOptions{}, fmt.Errorf("lint.language.require: неизвестный язык %q (допустимо: ru, en, off)", language.Require)
+	}
+	if language.MinRatio < 0 || language.MinRatio > 1 {
+		return spec.LintOptions{}, fmt.Errorf("lint.language.min_ratio должен быть в диапазоне от 0 до 1")
+	}
+	if language.MinWords < 0 {
+		return spec.LintOptions{}, fmt.Errorf("lint.language.min_words не может быть отрицательным")
+	}
+
+	for _, rule := range cfg.Lint.Disable {
+		if !slices.Contains(spec.LintRules, rule) {
+			return spec.LintOptions{}, fmt.Errorf("lint.disable: неизвестное правило %q", rule)
+		}
+	}
+
+	return spec.LintOptions{
+		Root: cfg.Root,
+		Language: spec.LanguageOptions{
+			Require:  language.Require,
+			MinRatio: language.MinRatio,
+			MinWords: language.MinWords,
+		},
+		Disabled:   cfg.Lint.Disable,
+		HTTPLayers: cfg.Lint.HTTPLayers,
+	}, nil
+}
+
+// RulePluginsFor returns the rule plugins configured in cfg. When names are
+// given, only those plugins are returned and unknown names are an error.
+func RulePluginsFor(cfg *Config, names ...string) ([]RulePlugin, error) {
+	var plugins []RulePlugin
+	for _, rule := range cfg.Rules {
+		if len(names) > 0 && !slices.Contains(names, rule.Name) {
+			continue
+		}
+		plugins = append(plugins, spec.RulePlugin{
+			Name:    rule.Name,
+			Command: rule.Command,
+			Args:    rule.Args,
+			Timeout: rule.Timeout,
+		})
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(cfg.Rules, func(rule config.RuleConfig) bool { return rule.Name == name }) {
+			return nil, fmt.Errorf("правило %s не найдено в config.yaml", name)
+		}
+	}
+
+	return plugins, nil
+}
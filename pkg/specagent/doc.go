@@ -0,0 +1,21 @@
+// Package specagent is the public Go API of spec-agent.
+//
+// It loads a project from .spec_agent/config.yaml, parses specifications,
+// builds the dependency graph, runs lint rules, formats specs and exports
+// HTML. Nothing in this package prints: results are returned to the caller,
+// and paths and settings are passed through options structs.
+//
+//	project, err := specagent.Open(specagent.Options{Dir: "path/to/repo"})
+//	if err != nil {
+//		return err
+//	}
+//	for _, d := range project.Lint() {
+//		fmt.Println(d.File, d.Line, d.Message)
+//	}
+//
+// Section heading names belong to a project: Open builds them from
+// sections.language and sections.aliases and uses them for its cache and
+// LintOptions. Functions working without a project take the names as an
+// argument, nil meaning DefaultSectionNames, so several projects can be used
+// concurrently in one process.
+package specagent
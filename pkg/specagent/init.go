@@ -0,0 +1,29 @@
+package specagent
+
+import (
+	agentfs "github.com/SmirnovND/spec-agent/internal/fs"
+)
+
+type (
+	InitOptions = agentfs.InitOptions
+	InitResult  = agentfs.InitResult
+)
+
+// Init creates .spec_agent and spec_changes in opts.Dir from the built-in
+// assets. Existing files are kept unless opts.Force or, for unmodified
+// assets, opts.Upgrade is set.
+func Init(opts InitOptions) (*InitResult, error) {
+	return agentfs.InitSpecAgent(opts)
+}
+
+// FindModuleRoot returns the nearest directory above start containing
+// go.mod, or start and false when there is none.
+func FindModuleRoot(start string) (string, bool) {
+	return agentfs.FindModuleRoot(start)
+}
+
+// DetectRoots returns the directories under dir named after project layers
+// (controllers, handlers, services, repositories, ...) to use as roots.
+func DetectRoots(dir string) []string {
+	return agentfs.DetectRoots(dir)
+}
@@ -0,0 +1,215 @@
+package specagent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+// Project is a loaded spec tree: the discovered spec files, the root specs
+// nobody links to and the dependency graph built from them.
+type Project struct {
+	Config      *Config
+	Files       []string
+	RootSpecs   []string
+	Graph       *Graph
+	Cache       *Cache
+	LintOptions LintOptions
+}
+
+// ExportOptions control HTML export. Empty fields fall back to the
+// project configuration.
+type ExportOptions struct {
+	OutputDir    string
+	TemplatesDir string
+	Theme        string
+	Workers      int
+	Force        bool
+}
+
+// Open loads the configuration and builds the project graph from its roots.
+func Open(opts Options) (*Project, error) {
+	cfg, err := LoadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := SectionNamesFor(cfg, opts.SectionLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	lint, err := LintOptionsFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lint.Sections = names
+
+	cache := opts.Cache
+	if cache == nil {
+		cache = spec.NewCache()
+	}
+	cache.SetSectionNames(names)
+
+	project, err := spec.LoadProject(cfg.Roots, DiscoverOptionsFor(cfg), cache)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Project{
+		Config:      cfg,
+		Files:       project.Files,
+		RootSpecs:   project.RootSpecs,
+		Graph:       project.Graph,
+		Cache:       project.Cache,
+		LintOptions: lint,
+	}, nil
+}
+
+// OpenVerified opens the project like Open and checks it: roots, unreadable
+// files, broken links and specs unreachable from any root. When the project
+// cannot be loaded and the roots have problems, missing roots or roots
+// without specs, it returns those diagnostics with a nil project instead of
+// an error.
+func OpenVerified(opts Options) (*Project, []Diagnostic, error) {
+	project, err := Open(opts)
+	if err == nil {
+		return project, project.Verify(), nil
+	}
+
+	cfg, cfgErr := LoadConfig(opts)
+	if cfgErr != nil {
+		return nil, nil, err
+	}
+	if diagnostics := VerifyRoots(cfg); len(diagnostics) > 0 {
+		return nil, diagnostics, nil
+	}
+	return nil, nil, err
+}
+
+// VerifyRoots reports roots of cfg that do not exist or contain no specs.
+// The diagnostics point to config.yaml.
+func VerifyRoots(cfg *Config) []Diagnostic {
+	return spec.VerifyRoots(spec.VerifyOptions{
+		Roots:    cfg.Roots,
+		Config:   cfg.Path,
+		Discover: DiscoverOptionsFor(cfg),
+	})
+}
+
+// Discover lists every file under the configured roots together with the
+// reason it was skipped, if it was.
+func Discover(cfg *Config) ([]DiscoveredFile, error) {
+	if len(cfg.Roots) == 0 {
+		return nil, fmt.Errorf("в config.yaml не указаны roots")
+	}
+	return spec.Discover(cfg.Roots, DiscoverOptionsFor(cfg))
+}
+
+// SectionNames returns the section heading names the project is parsed
+// and linted with.
+func (p *Project) SectionNames() *SectionNames {
+	return p.LintOptions.Sections
+}
+
+// Spec returns the parsed spec at path.
+func (p *Project) Spec(path string) (*Spec, error) {
+	return p.Cache.ParseFile(path)
+}
+
+// Specs parses every spec of the graph, keyed by absolute path.
+func (p *Project) Specs() map[string]*Spec {
+	return p.internal().Specs()
+}
+
+// Paths returns the absolute paths of all graph nodes in sorted order.
+func (p *Project) Paths() []string {
+	return p.internal().Paths()
+}
+
+// Lint runs the built-in lint rules over the whole graph.
+func (p *Project) Lint() []Diagnostic {
+	return spec.Lint(p.Graph, p.Cache, p.LintOptions)
+}
+
+// Verify checks the roots and the graph: unreadable files, broken links and
+// specs unreachable from any root.
+func (p *Project) Verify() []Diagnostic {
+	return append(VerifyRoots(p.Config), spec.VerifyGraph(p.internal())...)
+}
+
+// Dependencies returns the specs path links to.
+func (p *Project) Dependencies(path string) []string {
+	return spec.Dependencies(p.Graph, path)
+}
+
+// Dependents returns the specs linking to path.
+func (p *Project) Dependents(path string) []string {
+	return spec.Dependents(p.Graph, path)
+}
+
+// Impact returns every spec transitively depending on path with its depth.
+func (p *Project) Impact(path string) []ImpactEntry {
+	return spec.Impact(p.Graph, path)
+}
+
+// RunRules runs the rule plugins configured in rules of config.yaml, or only
+// the named ones, and returns their findings. The specs and the graph are
+// passed to every plugin as JSON on stdin.
+func (p *Project) RunRules(ctx context.Context, names ...string) ([]Diagnostic, error) {
+	plugins, err := RulePluginsFor(p.Config, names...)
+	if err != nil {
+		return nil, err
+	}
+	return spec.RunRulePlugins(ctx, p.internal(), p.Config.Root, plugins)
+}
+
+// Export renders the graph to static HTML.
+func (p *Project) Export(opts ExportOptions) (*ExportResult, error) {
+	if opts.OutputDir == "" {
+		opts.OutputDir = p.Config.BuildDir()
+	}
+	if opts.TemplatesDir == "" {
+		opts.TemplatesDir = p.Config.TemplatesDir()
+	}
+	if opts.Theme == "" {
+		opts.Theme = p.Config.Export.Theme
+	}
+
+	result, err := spec.ExportToHTML(p.Graph, spec.ExportOptions{
+		Root:         p.Config.Root,
+		OutputDir:    opts.OutputDir,
+		TemplatesDir: opts.TemplatesDir,
+		Theme:        opts.Theme,
+		Cache:        p.Cache,
+		Workers:      opts.Workers,
+		Force:        opts.Force,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при экспорте: %w", err)
+	}
+	return result, nil
+}
+
+// Sequence builds the sequence diagram of the Flow section of path,
+// following called specs up to depth levels.
+func (p *Project) Sequence(path string, depth int) (*Sequence, error) {
+	return spec.BuildSequence(path, depth, p.Cache)
+}
+
+// Context bundles the spec subtree of entry for an LLM, dependencies first
+// and entry last.
+func (p *Project) Context(entry string, opts ContextOptions) (*ContextBundle, error) {
+	return spec.BuildContext(entry, p.Cache, opts)
+}
+
+func (p *Project) internal() *spec.Project {
+	return &spec.Project{
+		Files:     p.Files,
+		RootSpecs: p.RootSpecs,
+		Graph:     p.Graph,
+		Cache:     p.Cache,
+		Lint:      p.LintOptions,
+	}
+}
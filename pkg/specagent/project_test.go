@@ -0,0 +1,52 @@
+package specagent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenVerifiedEmptyRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".spec_agent", "config.yaml"), "roots:\n  - internal/services\n")
+	if err := os.MkdirAll(filepath.Join(root, "internal", "services"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	project, diagnostics, err := OpenVerified(Options{Dir: root})
+	if err != nil {
+		t.Fatalf("ожидались диагностики вместо ошибки: %v", err)
+	}
+	if project != nil {
+		t.Errorf("проект без спецификаций не должен загружаться")
+	}
+	if len(diagnostics) != 1 || diagnostics[0].RuleID != "unresolved-root" || diagnostics[0].Severity != SeverityWarning {
+		t.Fatalf("ожидалось предупреждение unresolved-root, получено %+v", diagnostics)
+	}
+	if diagnostics[0].Line != 2 {
+		t.Errorf("диагностика указывает на строку %d config.yaml, ожидалась 2", diagnostics[0].Line)
+	}
+}
+
+func TestOpenVerifiedMissingRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".spec_agent", "config.yaml"), "roots:\n  - missing\n")
+
+	project, diagnostics, err := OpenVerified(Options{Dir: root})
+	if err != nil || project != nil {
+		t.Fatalf("ожидались только диагностики: project=%v err=%v", project, err)
+	}
+	if !HasErrors(diagnostics) {
+		t.Errorf("несуществующий root должен быть ошибкой: %+v", diagnostics)
+	}
+}
@@ -0,0 +1,103 @@
+package specagent
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	agentfs "github.com/SmirnovND/spec-agent/internal/fs"
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+// PromptsDir is the directory, relative to the project root, where prompts
+// can be overridden.
+const PromptsDir = agentfs.PromptsDir
+
+// SpecRulesPrompt is the prompt holding the specification rules.
+const SpecRulesPrompt = "spec_rules.md"
+
+// DefaultLayers lists the project layers in call order. It is used when no
+// roots are configured and to order the layers found in roots.
+var DefaultLayers = agentfs.DefaultLayers
+
+// PromptVars are the variables available to prompt templates.
+type PromptVars = agentfs.PromptVars
+
+// Prompts returns the prompts of cfg: files in its prompts directory
+// override the built-in ones. A nil cfg yields the built-in prompts.
+func Prompts(cfg *Config) fs.FS {
+	if cfg == nil {
+		return agentfs.Prompts("")
+	}
+	return agentfs.Prompts(cfg.PromptsDir())
+}
+
+// RenderPrompt renders the prompt name from prompts as a text/template.
+func RenderPrompt(prompts fs.FS, name string, vars PromptVars) (string, error) {
+	return agentfs.RenderPrompt(prompts, name, vars)
+}
+
+// PromptVarsFor returns the template variables of cfg, with SpecRules
+// rendered from prompts. SpecRulesSource is left for the caller. A nil cfg
+// yields the defaults: the default layers and Russian specs.
+func PromptVarsFor(cfg *Config, prompts fs.FS) (PromptVars, error) {
+	now := time.Now()
+	vars := PromptVars{
+		Layers:       DefaultLayers,
+		LanguageCode: spec.LanguageRussian,
+		Date:         now.Format("2006-01-02"),
+		PlanPrefix:   now.Format("20060102_1504"),
+	}
+
+	if cfg != nil {
+		var layers []string
+		for _, root := range cfg.Roots {
+			if rel, err := filepath.Rel(cfg.Root, root); err == nil {
+				root = rel
+			}
+			vars.Roots = append(vars.Roots, filepath.ToSlash(root))
+			if layer := path.Base(filepath.ToSlash(root)); !slices.Contains(layers, layer) {
+				layers = append(layers, layer)
+			}
+		}
+		if len(layers) > 0 {
+			vars.Layers = orderLayers(layers)
+		}
+		if cfg.Lint.Language.Require != "" {
+			vars.LanguageCode = cfg.Lint.Language.Require
+		}
+	}
+
+	switch vars.LanguageCode {
+	case spec.LanguageRussian:
+		vars.Language = "Russian"
+	case spec.LanguageEnglish:
+		vars.Language = "English"
+	}
+
+	rules, err := RenderPrompt(prompts, SpecRulesPrompt, vars)
+	if err != nil {
+		return vars, fmt.Errorf("не удалось прочитать правила спецификаций: %w", err)
+	}
+	vars.SpecRules = rules
+
+	return vars, nil
+}
+
+func orderLayers(layers []string) []string {
+	var ordered []string
+	for _, layer := range DefaultLayers {
+		if slices.Contains(layers, layer) {
+			ordered = append(ordered, layer)
+		}
+	}
+	for _, layer := range layers {
+		if !slices.Contains(ordered, layer) {
+			ordered = append(ordered, layer)
+		}
+	}
+	return ordered
+}
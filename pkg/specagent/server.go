@@ -0,0 +1,122 @@
+package specagent
+
+import (
+	"log"
+	"time"
+
+	"github.com/SmirnovND/spec-agent/internal/lsp"
+	"github.com/SmirnovND/spec-agent/internal/mcp"
+	"github.com/SmirnovND/spec-agent/internal/server"
+	"github.com/SmirnovND/spec-agent/internal/spec"
+	"github.com/SmirnovND/spec-agent/internal/watch"
+)
+
+type (
+	Server    = server.Server
+	LSPServer = lsp.Server
+	MCPServer = mcp.Server
+	Poller    = watch.Poller
+)
+
+// ProjectLoader loads the current project state for a server request.
+type ProjectLoader func() (*Project, error)
+
+// ServerOptions configures NewServer. Loader backs the JSON API and the
+// editor; the static site is served from BuildDir.
+type ServerOptions struct {
+	Addr            string
+	BuildDir        string
+	Root            string
+	Loader          ProjectLoader
+	LiveReload      bool
+	Edit            bool
+	BasicAuth       string
+	TemplatesDir    string
+	OnSave          func(path string)
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	Logger          *log.Logger
+}
+
+// LSPOptions configures NewLSPServer. Without Load only the open document
+// is linted and navigated.
+type LSPOptions struct {
+	Load    ProjectLoader
+	Version string
+	Logger  *log.Logger
+}
+
+// MCPOptions configures NewMCPServer.
+type MCPOptions struct {
+	Load    ProjectLoader
+	Root    string
+	Version string
+}
+
+// Loader returns a ProjectLoader that opens the project with opts on every
+// call, so the servers see edited specs. Pass a shared opts.Cache to reparse
+// only the files invalidated in it.
+func Loader(opts Options) ProjectLoader {
+	return func() (*Project, error) {
+		return Open(opts)
+	}
+}
+
+func (load ProjectLoader) internal() spec.ProjectLoader {
+	if load == nil {
+		return nil
+	}
+	return func() (*spec.Project, error) {
+		project, err := load()
+		if err != nil || project == nil {
+			return nil, err
+		}
+		return project.internal(), nil
+	}
+}
+
+// NewServer returns the HTTP server of serve: the static export, the JSON
+// API, live reload and the editor.
+func NewServer(opts ServerOptions) *Server {
+	return server.New(server.Options{
+		Addr:            opts.Addr,
+		BuildDir:        opts.BuildDir,
+		Root:            opts.Root,
+		Loader:          opts.Loader.internal(),
+		LiveReload:      opts.LiveReload,
+		Edit:            opts.Edit,
+		BasicAuth:       opts.BasicAuth,
+		TemplatesDir:    opts.TemplatesDir,
+		OnSave:          opts.OnSave,
+		ReadTimeout:     opts.ReadTimeout,
+		WriteTimeout:    opts.WriteTimeout,
+		IdleTimeout:     opts.IdleTimeout,
+		ShutdownTimeout: opts.ShutdownTimeout,
+		Logger:          opts.Logger,
+	})
+}
+
+// NewLSPServer returns a Language Server for spec markdown files.
+func NewLSPServer(opts LSPOptions) *LSPServer {
+	return lsp.NewServer(lsp.Options{Load: opts.Load.internal(), Version: opts.Version, Logger: opts.Logger})
+}
+
+// NewMCPServer returns a Model Context Protocol server exposing spec tools.
+func NewMCPServer(opts MCPOptions) *MCPServer {
+	return mcp.NewServer(mcp.Options{Load: opts.Load.internal(), Root: opts.Root, Version: opts.Version})
+}
+
+// NewPoller returns a poller reporting changed files under paths every
+// interval.
+func NewPoller(paths []string, interval time.Duration) *Poller {
+	return watch.NewPoller(paths, interval)
+}
+
+// WatchPaths returns what serve --watch polls: the roots, the templates
+// directory and config.yaml of cfg.
+func WatchPaths(cfg *Config) []string {
+	paths := append([]string{}, cfg.Roots...)
+	return append(paths, cfg.TemplatesDir(), cfg.Path)
+}
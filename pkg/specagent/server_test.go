@@ -0,0 +1,44 @@
+package specagent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewServerCustomLoader(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".spec_agent", "config.yaml"), "roots:\n  - specs\n")
+	writeFile(t, filepath.Join(root, "specs", "api.md"), "# API\n\n## Dependencies\n- [Gone](gone.md)\n")
+
+	calls := 0
+	load := func() (*Project, error) {
+		calls++
+		return Open(Options{Dir: root})
+	}
+
+	srv := NewServer(ServerOptions{Root: root, Loader: load, BuildDir: t.TempDir()})
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/lint", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/lint: %d %s", rec.Code, rec.Body)
+	}
+	if calls == 0 {
+		t.Error("загрузчик проекта не вызван")
+	}
+
+	var report DiagnosticsJSON
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, d := range report.Diagnostics {
+		found = found || d.Rule == "broken-link"
+	}
+	if !found {
+		t.Errorf("ожидалась диагностика broken-link, получено %+v", report.Diagnostics)
+	}
+}
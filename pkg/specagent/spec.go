@@ -0,0 +1,109 @@
+package specagent
+
+import (
+	"io"
+
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+// NewCache returns an empty cache of parsed specs.
+func NewCache() *Cache {
+	return spec.NewCache()
+}
+
+// DefaultSectionNames returns the section heading names used when none are
+// given: English canonical names with the built-in aliases.
+func DefaultSectionNames() *SectionNames {
+	return spec.DefaultSectionNames()
+}
+
+// ParseFile reads and parses the spec at path, recognizing section headings
+// by names. Nil names mean DefaultSectionNames.
+func ParseFile(path string, names *SectionNames) (*Spec, error) {
+	return spec.ParseFileWith(path, names)
+}
+
+// ParseContent parses spec markdown as if it were read from path.
+func ParseContent(path, content string, names *SectionNames) *Spec {
+	return spec.ParseContentWith(path, content, names)
+}
+
+// BuildGraph determines the root specs among files (those nobody links to)
+// and builds the dependency graph from them.
+func BuildGraph(files []string, cache *Cache) (*Graph, []string, error) {
+	if cache == nil {
+		cache = spec.NewCache()
+	}
+
+	roots := spec.FindRootSpecs(files, cache.CollectAllReferences(files))
+	graph, err := cache.BuildGraphFromRoots(roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph, roots, nil
+}
+
+// LintSpec runs the built-in lint rules over a single spec.
+func LintSpec(s *Spec, opts LintOptions) []Diagnostic {
+	return spec.LintSpec(s, opts)
+}
+
+// HasErrors reports whether any diagnostic has error severity.
+func HasErrors(diagnostics []Diagnostic) bool {
+	return spec.HasErrors(diagnostics)
+}
+
+// Format returns the canonical form of s: sections in canonical order,
+// renumbered lists, normalized Flow references and sorted Dependencies.
+// Added sections are named after names.
+func Format(s *Spec, names *SectionNames) string {
+	return spec.Format(s, names)
+}
+
+// NormalizeSectionHeadings renames recognized section headings of s to the
+// canonical names and returns the new content and the number of renamed
+// headings.
+func NormalizeSectionHeadings(s *Spec, names *SectionNames) (string, int) {
+	return spec.NormalizeSectionHeadings(s, names)
+}
+
+// UnifiedDiff returns a unified diff between a and b.
+func UnifiedDiff(from, to, a, b string) string {
+	return spec.UnifiedDiff(from, to, a, b)
+}
+
+// RelPath returns path relative to the working directory with forward slashes.
+func RelPath(path string) string {
+	return spec.RelPath(path)
+}
+
+// BuildContext bundles entry and the specs it transitively depends on for an
+// LLM without loading a project configuration.
+func BuildContext(entry string, names *SectionNames, opts ContextOptions) (*ContextBundle, error) {
+	return spec.BuildContext(entry, newCache(names), opts)
+}
+
+// BuildSequence builds the sequence diagram of the Flow section of the spec
+// at path without loading a project configuration, following called specs
+// up to depth levels.
+func BuildSequence(path string, depth int, names *SectionNames) (*Sequence, error) {
+	return spec.BuildSequence(path, depth, newCache(names))
+}
+
+func newCache(names *SectionNames) *Cache {
+	cache := spec.NewCache()
+	cache.SetSectionNames(names)
+	return cache
+}
+
+// WriteDiagnostics writes diagnostics to w as text, JSON, SARIF 2.1.0 or
+// GitHub Actions workflow commands. Paths are made relative to opts.Root in
+// all formats but text.
+func WriteDiagnostics(w io.Writer, diagnostics []Diagnostic, opts ReportOptions) error {
+	return spec.WriteDiagnostics(w, diagnostics, opts)
+}
+
+// CountSeverities returns the number of errors and warnings in diagnostics.
+func CountSeverities(diagnostics []Diagnostic) (errors, warnings int) {
+	return spec.CountSeverities(diagnostics)
+}
@@ -0,0 +1,80 @@
+package specagent
+
+import (
+	"github.com/SmirnovND/spec-agent/internal/config"
+	"github.com/SmirnovND/spec-agent/internal/spec"
+)
+
+type (
+	Config = config.Config
+
+	Spec         = spec.Spec
+	Section      = spec.Section
+	SectionKind  = spec.SectionKind
+	SectionNames = spec.SectionNames
+	Position     = spec.Position
+	Range        = spec.Range
+	Heading      = spec.Heading
+	SpecLink     = spec.SpecLink
+	SpecRef      = spec.SpecRef
+	Item         = spec.Item
+	BusinessRule = spec.BusinessRule
+	FlowStep     = spec.FlowStep
+	Dependency   = spec.Dependency
+	ErrorDef     = spec.ErrorDef
+
+	Graph       = spec.Graph
+	Node        = spec.Node
+	Edge        = spec.Edge
+	ImpactEntry = spec.ImpactEntry
+	Cache       = spec.Cache
+
+	Diagnostic      = spec.Diagnostic
+	ReportOptions   = spec.ReportOptions
+	DiagnosticsJSON = spec.DiagnosticsJSON
+	DiagnosticJSON  = spec.DiagnosticJSON
+	LintOptions     = spec.LintOptions
+	LanguageOptions = spec.LanguageOptions
+	RulePlugin      = spec.RulePlugin
+
+	DiscoverOptions = spec.DiscoverOptions
+	DiscoveredFile  = spec.DiscoveredFile
+
+	ExportResult    = spec.ExportResult
+	ContextOptions  = spec.ContextOptions
+	ContextBundle   = spec.ContextBundle
+	Sequence        = spec.Sequence
+	Participant     = spec.Participant
+	SequenceMessage = spec.SequenceMessage
+)
+
+const (
+	SeverityError   = spec.SeverityError
+	SeverityWarning = spec.SeverityWarning
+
+	FormatText   = spec.FormatText
+	FormatJSON   = spec.FormatJSON
+	FormatSARIF  = spec.FormatSARIF
+	FormatGitHub = spec.FormatGitHub
+
+	SectionResponsibility = spec.SectionResponsibility
+	SectionInputs         = spec.SectionInputs
+	SectionOutputs        = spec.SectionOutputs
+	SectionBusinessRules  = spec.SectionBusinessRules
+	SectionFlow           = spec.SectionFlow
+	SectionDependencies   = spec.SectionDependencies
+	SectionErrors         = spec.SectionErrors
+	SectionNotes          = spec.SectionNotes
+)
+
+// ConfigEnvVar names the environment variable with the path to config.yaml.
+const ConfigEnvVar = config.EnvVar
+
+// ErrConfigNotFound is returned when no config.yaml is found by discovery.
+var ErrConfigNotFound = config.ErrNotFound
+
+// ReportFormats lists the formats accepted by WriteDiagnostics.
+var ReportFormats = spec.ReportFormats
+
+// LintRules lists the IDs of the built-in lint rules.
+var LintRules = spec.LintRules